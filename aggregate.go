@@ -0,0 +1,71 @@
+package monitoringplugin
+
+import "math"
+
+// AggregateFunc aggregates the values of a group of performance data points to a single value. It is only called with
+// at least one value.
+type AggregateFunc func(values []float64) float64
+
+// AggregateSum returns the sum of the values.
+func AggregateSum(values []float64) float64 {
+	var sum float64
+	for _, value := range values {
+		sum += value
+	}
+	return sum
+}
+
+// AggregateAvg returns the arithmetic mean of the values.
+func AggregateAvg(values []float64) float64 {
+	return AggregateSum(values) / float64(len(values))
+}
+
+// AggregateMin returns the smallest value.
+func AggregateMin(values []float64) float64 {
+	min := values[0]
+	for _, value := range values[1:] {
+		min = math.Min(min, value)
+	}
+	return min
+}
+
+// AggregateMax returns the largest value.
+func AggregateMax(values []float64) float64 {
+	max := values[0]
+	for _, value := range values[1:] {
+		max = math.Max(max, value)
+	}
+	return max
+}
+
+// Aggregate aggregates the values of all performance data points with the given metric, regardless of their labels.
+// Points whose value is unknown or not a number are skipped. If there are no values, NaN is returned.
+func (pd PerfData) Aggregate(metric string, fn AggregateFunc) float64 {
+	var values []float64
+	for _, key := range pd.performanceData.sortedKeys() {
+		if key.Metric != metric {
+			continue
+		}
+		if value, err := parseFloat(pd.performanceData[key].Value); err == nil {
+			values = append(values, value)
+		}
+	}
+	if len(values) == 0 {
+		return math.NaN()
+	}
+	return fn(values)
+}
+
+/*
+NewAggregatedDataPoint creates a derived PerformanceDataPoint (see NewDerivedDataPoint) whose value is aggregated from
+all performance data points with the source metric, e.g. the total traffic across all interfaces. Thresholds are
+applied to the aggregated value.
+Usage:
+	err := Response.AddPerformanceDataPoint(NewAggregatedDataPoint("if_in_octets", "if_in_octets", AggregateSum).
+		SetLabel("total").SetThresholds(NewThresholds(nil, 1e9, nil, 2e9)))
+*/
+func NewAggregatedDataPoint(metric, source string, fn AggregateFunc) *PerformanceDataPoint {
+	return NewDerivedDataPoint(metric, func(pd PerfData) float64 {
+		return pd.Aggregate(source, fn)
+	})
+}
@@ -0,0 +1,32 @@
+package monitoringplugin
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func TestAggregateFuncs(t *testing.T) {
+	values := []float64{4, 1, 7}
+	assert.Equal(t, 12.0, AggregateSum(values))
+	assert.Equal(t, 4.0, AggregateAvg(values))
+	assert.Equal(t, 1.0, AggregateMin(values))
+	assert.Equal(t, 7.0, AggregateMax(values))
+}
+
+func TestNewAggregatedDataPoint(t *testing.T) {
+	r := NewResponse("checked")
+	assert.NoError(t, r.AddMetrics(map[string]float64{"if_in_octets": 100}, WithMetricLabel("eth0")))
+	assert.NoError(t, r.AddMetrics(map[string]float64{"if_in_octets": 300}, WithMetricLabel("eth1")))
+	assert.NoError(t, r.AddPerformanceDataPoint(NewUnknownPerformanceDataPoint("if_in_octets").SetLabel("eth2")))
+	assert.NoError(t, r.AddPerformanceDataPoint(NewAggregatedDataPoint("if_in_octets_avg", "if_in_octets",
+		AggregateAvg)))
+	assert.NoError(t, r.AddPerformanceDataPoint(NewAggregatedDataPoint("if_in_octets", "if_in_octets",
+		AggregateSum).SetLabel("total").SetThresholds(NewThresholds(nil, 300, nil, 500))))
+
+	assert.Equal(t, WARNING, r.GetInfo().StatusCode)
+	assert.Equal(t, "'if_in_octets_eth0'=100 'if_in_octets_eth1'=300 'if_in_octets_eth2'=U "+
+		"'if_in_octets_avg'=200 'if_in_octets_total'=400;~:300;~:500;;", r.performanceDataOutput())
+
+	assert.True(t, math.IsNaN(PerfData{r.performanceData}.Aggregate("missing", AggregateSum)))
+}
@@ -0,0 +1,61 @@
+package monitoringplugin
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// asciiTransliterations contains readable ASCII replacements for common non-ASCII characters.
+var asciiTransliterations = map[rune]string{
+	'°':      "deg",
+	'µ':      "u",
+	'ä':      "ae",
+	'ö':      "oe",
+	'ü':      "ue",
+	'Ä':      "Ae",
+	'Ö':      "Oe",
+	'Ü':      "Ue",
+	'ß':      "ss",
+	'€':      "EUR",
+	'×':      "x",
+	'±':      "+/-",
+	'…':      "...",
+	'–':      "-",
+	'—':      "-",
+	'‘':      "'",
+	'’':      "'",
+	'“':      "\"",
+	'”':      "\"",
+	'\u00a0': " ",
+}
+
+/*
+ForceASCII activates or deactivates the ASCII-only output mode for legacy transports like NRPE v2 that mangle
+multibyte characters. Common characters are transliterated (e.g. "°C" -> "degC"), all other non-ASCII characters are
+escaped as \uXXXX.
+*/
+func (r *Response) ForceASCII(b bool) {
+	r.forceASCII = b
+}
+
+// toASCII transliterates or escapes all non-ASCII characters. Invalid UTF-8 bytes are escaped as \xXX.
+func toASCII(output []byte) []byte {
+	res := make([]byte, 0, len(output))
+	for len(output) > 0 {
+		c, size := utf8.DecodeRune(output)
+		switch {
+		case c < utf8.RuneSelf:
+			res = append(res, output[0])
+		case c == utf8.RuneError && size == 1:
+			res = append(res, fmt.Sprintf("\\x%02x", output[0])...)
+		case asciiTransliterations[c] != "":
+			res = append(res, asciiTransliterations[c]...)
+		case c > 0xffff:
+			res = append(res, fmt.Sprintf("\\U%08x", c)...)
+		default:
+			res = append(res, fmt.Sprintf("\\u%04x", c)...)
+		}
+		output = output[size:]
+	}
+	return res
+}
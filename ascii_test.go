@@ -0,0 +1,25 @@
+package monitoringplugin
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestToASCII(t *testing.T) {
+	assert.Equal(t, "temperature is 32degC", string(toASCII([]byte("temperature is 32°C"))))
+	assert.Equal(t, "Groesse: 5 x 10 ... - \"ok\"", string(toASCII([]byte("Größe: 5 × 10 … – “ok”"))))
+	assert.Equal(t, "\\u65e5\\u672c \\U0001f525 \\xff", string(toASCII([]byte("日本 🔥 \xff"))))
+	assert.Equal(t, "plain ascii | 'a'=1", string(toASCII([]byte("plain ascii | 'a'=1"))))
+}
+
+func TestResponse_ForceASCII(t *testing.T) {
+	r := NewResponse("checked")
+	r.UpdateStatus(WARNING, "temperature is 32°C")
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("temperature", 32).SetUnit("°C")))
+	assert.Equal(t, "WARNING: temperature is 32°C | 'temperature'=32°C", r.String())
+
+	r.ForceASCII(true)
+	assert.Equal(t, "WARNING: temperature is 32degC | 'temperature'=32degC", r.String())
+	output, _ := r.Output()
+	assert.Equal(t, "WARNING: temperature is 32degC | 'temperature'=32degC\n", string(output))
+}
@@ -0,0 +1,114 @@
+package monitoringplugin
+
+import (
+	"encoding/json"
+	"github.com/pkg/errors"
+	"os"
+	"strings"
+	"time"
+)
+
+// defaultAuditMaxSize is the default size in bytes at which the audit log file is rotated.
+const defaultAuditMaxSize = 10 * 1024 * 1024
+
+// FileAuditSink is a Sink that appends one JSON line per execution of the check plugin to a file.
+// If the file exceeds its maximum size, it is rotated to <path>.1 before the next line is written.
+type FileAuditSink struct {
+	path    string
+	maxSize int64
+	host    string
+	service string
+}
+
+// auditEntry is one line of the audit log.
+type auditEntry struct {
+	Timestamp time.Time `json:"timestamp"`
+	Host      string    `json:"host,omitempty"`
+	Service   string    `json:"service,omitempty"`
+	Status    Status    `json:"status"`
+	FirstLine string    `json:"first_line"`
+	Runtime   float64   `json:"runtime"`
+}
+
+/*
+NewFileAuditSink creates a new FileAuditSink writing to the given path. Host and service default to the environment
+variables NAGIOS_HOSTNAME and NAGIOS_SERVICEDESC, the maximum size of the file defaults to 10 MiB.
+Usage:
+	Response.AddSink(NewFileAuditSink("/var/log/check_foo.audit.log").SetService("foo"))
+*/
+func NewFileAuditSink(path string) *FileAuditSink {
+	return &FileAuditSink{
+		path:    path,
+		maxSize: defaultAuditMaxSize,
+		host:    os.Getenv("NAGIOS_HOSTNAME"),
+		service: os.Getenv("NAGIOS_SERVICEDESC"),
+	}
+}
+
+// SetMaxSize sets the size in bytes at which the audit log file is rotated. A value of 0 or less disables rotation.
+func (s *FileAuditSink) SetMaxSize(maxSize int64) *FileAuditSink {
+	s.maxSize = maxSize
+	return s
+}
+
+// SetHost sets the host name that is written to the audit log.
+func (s *FileAuditSink) SetHost(host string) *FileAuditSink {
+	s.host = host
+	return s
+}
+
+// SetService sets the service name that is written to the audit log.
+func (s *FileAuditSink) SetService(service string) *FileAuditSink {
+	s.service = service
+	return s
+}
+
+// Write appends the result to the audit log file.
+func (s *FileAuditSink) Write(info ResponseInfo) error {
+	firstLine := strings.SplitN(info.RawOutput, "\n", 2)[0]
+	firstLine = strings.SplitN(firstLine, " | ", 2)[0]
+	line, err := json.Marshal(auditEntry{
+		Timestamp: time.Now(),
+		Host:      s.host,
+		Service:   s.service,
+		Status:    info.StatusCode,
+		FirstLine: firstLine,
+		Runtime:   info.Runtime.Seconds(),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal audit entry")
+	}
+	line = append(line, '\n')
+
+	if err := s.rotate(int64(len(line))); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return errors.Wrap(err, "failed to open audit log")
+	}
+	if _, err := f.Write(line); err != nil {
+		_ = f.Close()
+		return errors.Wrap(err, "failed to write audit log")
+	}
+	return errors.Wrap(f.Close(), "failed to close audit log")
+}
+
+// rotate renames the audit log file to <path>.1 if writing n more bytes would exceed the maximum size.
+func (s *FileAuditSink) rotate(n int64) error {
+	if s.maxSize <= 0 {
+		return nil
+	}
+	stat, err := os.Stat(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to stat audit log")
+	}
+	if stat.Size() == 0 || stat.Size()+n <= s.maxSize {
+		return nil
+	}
+	return errors.Wrap(os.Rename(s.path, s.path+".1"), "failed to rotate audit log")
+}
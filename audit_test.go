@@ -0,0 +1,49 @@
+package monitoringplugin
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileAuditSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "monitoringplugin")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "audit.log")
+
+	sink := NewFileAuditSink(path).SetHost("host").SetService("service").SetMaxSize(400)
+	r := NewResponse("checked")
+	r.UpdateStatus(WARNING, "warning")
+	r.UpdateStatus(OK, "ok")
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("metric", 1)))
+	r.SetOutputDelimiter(" / ")
+	assert.NoError(t, sink.Write(r.GetInfo()))
+
+	content, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	var entry map[string]interface{}
+	assert.NoError(t, json.Unmarshal(content, &entry))
+	assert.Equal(t, "host", entry["host"])
+	assert.Equal(t, "service", entry["service"])
+	assert.Equal(t, float64(WARNING), entry["status"])
+	assert.Equal(t, "WARNING: warning / ok", entry["first_line"])
+
+	assert.NoError(t, sink.Write(r.GetInfo()))
+	content, err = ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Len(t, strings.Split(strings.TrimSpace(string(content)), "\n"), 2)
+
+	assert.NoError(t, sink.Write(r.GetInfo()))
+	content, err = ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Len(t, strings.Split(strings.TrimSpace(string(content)), "\n"), 1)
+	_, err = os.Stat(path + ".1")
+	assert.NoError(t, err)
+}
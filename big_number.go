@@ -0,0 +1,21 @@
+package monitoringplugin
+
+import "math/big"
+
+// numberPrecision is the precision (in bits) that is used to parse values, min, max and thresholds of performance data
+// points, so integers with up to 512 bits (e.g. 128-bit SNMP counters) are compared exactly.
+const numberPrecision = 512
+
+/*
+NewBigIntDataPoint creates a new PerformanceDataPoint for a value that does not fit in an int64 or float64, e.g. a
+128-bit SNMP counter. The value is rendered as exact decimal number and compared exactly against min, max and
+thresholds, which can be *big.Int values as well. The value is copied, so later changes to it do not affect the
+PerformanceDataPoint.
+Usage:
+	value, _ := new(big.Int).SetString("340282366920938463463374607431768211455", 10)
+	PerformanceDataPoint := NewBigIntDataPoint("ifHCInOctets", value).SetCounter()
+	//'ifHCInOctets'=340282366920938463463374607431768211455c
+*/
+func NewBigIntDataPoint(metric string, value *big.Int) *PerformanceDataPoint {
+	return NewPerformanceDataPoint(metric, new(big.Int).Set(value))
+}
@@ -0,0 +1,35 @@
+package monitoringplugin
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"testing"
+)
+
+func TestNewBigIntDataPoint(t *testing.T) {
+	value, _ := new(big.Int).SetString("340282366920938463463374607431768211455", 10)
+	max, _ := new(big.Int).SetString("340282366920938463463374607431768211456", 10)
+	p := NewBigIntDataPoint("ifHCInOctets", value).SetCounter().SetMin(0).SetMax(max)
+	value.SetInt64(1)
+	assert.NoError(t, p.Validate())
+	assert.Equal(t, "'ifHCInOctets'=340282366920938463463374607431768211455c;;;0;340282366920938463463374607431768211456",
+		string(p.output(false)))
+
+	below, _ := new(big.Int).SetString("340282366920938463463374607431768211454", 10)
+	assert.Error(t, NewBigIntDataPoint("ifHCInOctets", max).SetMax(below.Add(below, big.NewInt(1))).Validate())
+
+	r := NewResponse("checked")
+	warn, _ := new(big.Int).SetString("100000000000000000000000000000", 10)
+	balance, _ := new(big.Int).SetString("100000000000000000000000000001", 10)
+	assert.NoError(t, r.AddPerformanceDataPoint(NewBigIntDataPoint("balance", balance).
+		SetThresholds(NewThresholds(nil, warn, nil, nil))))
+	assert.Equal(t, WARNING, r.GetStatusCode())
+
+	b, err := json.Marshal(r.GetInfo())
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), `"value":100000000000000000000000000001`)
+	var decoded ResponseInfo
+	assert.NoError(t, json.Unmarshal(b, &decoded))
+	assert.Equal(t, balance, decoded.PerformanceData[0].Value)
+}
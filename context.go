@@ -0,0 +1,52 @@
+package monitoringplugin
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+/*
+RunWithContext runs the check function and waits until it returns or the context is done.
+The check function gets a context that is canceled when RunWithContext returns and its own Response with the
+configuration of this Response (like the tasks of RunParallel), which is merged into this Response if the check
+function returns in time.
+If the context's deadline is exceeded before the check returns, the status is set to UNKNOWN with the message
+"plugin timed out after Xs", if the context is canceled, the status is set to UNKNOWN with the message
+"plugin was canceled". In both cases the results of the check function are discarded, so a check function that is
+still running can not modify this Response. All performance data that was added to this Response before is still
+part of the response.
+Unlike a check function that only gets the context and modifies this Response directly, the check function gets a
+Response of its own, because a check that ignores the canceled context would otherwise keep modifying this Response
+while its output is rendered.
+Usage:
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err := Response.RunWithContext(ctx, func(ctx context.Context, r *Response) error {
+		//check plugin logic...
+	})
+*/
+func (r *Response) RunWithContext(ctx context.Context, check func(context.Context, *Response) error) error {
+	start := time.Now()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	result := r.taskResponse()
+	done := make(chan error, 1)
+	go func() {
+		done <- check(ctx, result)
+	}()
+
+	select {
+	case err := <-done:
+		r.merge(result)
+		return err
+	case <-ctx.Done():
+		if ctx.Err() == context.DeadlineExceeded {
+			elapsed := time.Since(start).Round(100 * time.Millisecond).Seconds()
+			r.UpdateStatus(UNKNOWN, "plugin timed out after "+strconv.FormatFloat(elapsed, 'f', -1, 64)+"s")
+		} else {
+			r.UpdateStatus(UNKNOWN, "plugin was canceled")
+		}
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,76 @@
+package monitoringplugin
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestResponse_RunWithContext(t *testing.T) {
+	r := NewResponse("checked")
+	err := r.RunWithContext(context.Background(), func(ctx context.Context, r *Response) error {
+		return r.AddPerformanceDataPoint(NewPerformanceDataPoint("metric", 1))
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, OK, r.statusCode)
+
+	err = r.RunWithContext(context.Background(), func(ctx context.Context, r *Response) error {
+		return errors.New("check failed")
+	})
+	assert.EqualError(t, err, "check failed")
+	assert.Equal(t, OK, r.statusCode)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	finished := make(chan struct{})
+	err = r.RunWithContext(ctx, func(ctx context.Context, r *Response) error {
+		defer close(finished)
+		<-ctx.Done()
+		time.Sleep(50 * time.Millisecond)
+		r.UpdateStatus(CRITICAL, "late result")
+		return r.AddPerformanceDataPoint(NewPerformanceDataPoint("late", 1))
+	})
+	assert.Equal(t, context.DeadlineExceeded, err)
+	res := r.GetInfo()
+	assert.Equal(t, "UNKNOWN: plugin timed out after 0.2s | 'metric'=1", res.RawOutput)
+	<-finished
+	assert.Equal(t, "UNKNOWN: plugin timed out after 0.2s | 'metric'=1", r.GetInfo().RawOutput)
+
+	r = NewResponse("checked")
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	err = r.RunWithContext(ctx, func(ctx context.Context, r *Response) error {
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	})
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, "UNKNOWN: plugin was canceled", r.GetInfo().RawOutput)
+}
+
+func TestResponse_RunWithContextCancel(t *testing.T) {
+	r := NewResponse("checked")
+	var checkCtx context.Context
+	err := r.RunWithContext(context.Background(), func(ctx context.Context, r *Response) error {
+		checkCtx = ctx
+		r.UpdateStatus(WARNING, "disk almost full")
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Error(t, checkCtx.Err())
+	assert.Equal(t, "WARNING: disk almost full", r.String())
+}
+
+
+func TestResponse_RunWithContextInfoMessages(t *testing.T) {
+	r := NewResponse("checked")
+	r.SetVerbose(true)
+	err := r.RunWithContext(context.Background(), func(ctx context.Context, r *Response) error {
+		r.AddInfoMessage("disk1 has 10 partitions")
+		r.SetSummary("1 disk checked")
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "OK: 1 disk checked\ndisk1 has 10 partitions", r.String())
+}
@@ -0,0 +1,180 @@
+package monitoringplugin
+
+import "math"
+
+// PerfData provides read access to the performance data of a Response. It is passed to the functions of derived
+// performance data points (see NewDerivedDataPoint).
+type PerfData struct {
+	performanceData performanceData
+}
+
+// Value returns the value of the PerformanceDataPoint with the given metric and label as float64. If there is no such
+// PerformanceDataPoint or its value is not a number, NaN is returned.
+func (pd PerfData) Value(metric, label string) float64 {
+	point, ok := pd.performanceData[performanceDataPointKey{metric, label}]
+	if !ok {
+		return math.NaN()
+	}
+	value, err := parseFloat(point.Value)
+	if err != nil {
+		return math.NaN()
+	}
+	return value
+}
+
+/*
+NewLazyPerformanceDataPoint creates a PerformanceDataPoint whose value is computed by the given function when the
+output is generated, so metrics about the check itself (e.g. elapsed time or retries used) can be registered early
+and captured at the very end. Like a derived point (see NewDerivedDataPoint), it is evaluated again whenever the output
+or the ResponseInfo is generated until the Response is finalized (see Response.Finalize()), so the function may be
+called several times and the printed output contains the value of the last call. If the function returns an error,
+the value is unknown ('U').
+Usage:
+	start := time.Now()
+	err := Response.AddPerformanceDataPoint(NewLazyPerformanceDataPoint("check_duration", func() (float64, error) {
+		return time.Since(start).Seconds(), nil
+	}).SetUnit("s"))
+*/
+func NewLazyPerformanceDataPoint(metric string, f func() (float64, error)) *PerformanceDataPoint {
+	return &PerformanceDataPoint{
+		Metric: metric,
+		lazy:   f,
+	}
+}
+
+/*
+NewDerivedDataPoint creates a PerformanceDataPoint whose value is computed from other performance data points when the
+output is generated, so ratios and sums stay consistent with the raw points they are derived from. The point is
+configured like other points and added with Response.AddPerformanceDataPoint(*PerformanceDataPoint). Derived points
+are computed whenever the output or the ResponseInfo is generated, so they always reflect the current raw points, and a
+last time when the Response is finalized (see Response.Finalize()). They are computed in the order they were added, so
+they can use previously derived points. Their thresholds are checked when they are computed. If the computed value is
+NaN or infinite, e.g. because a raw point is missing, the value is unknown ('U').
+Usage:
+	err := Response.AddPerformanceDataPoint(NewDerivedDataPoint("usage_pct", func(pd PerfData) float64 {
+		return pd.Value("used", "") / pd.Value("total", "") * 100
+	}).SetUnit("%").SetMin(0).SetMax(100))
+*/
+func NewDerivedDataPoint(metric string, compute func(pd PerfData) float64) *PerformanceDataPoint {
+	return &PerformanceDataPoint{
+		Metric: metric,
+		derive: compute,
+	}
+}
+
+// derivedState records what the last computation of the derived and lazy performance data points added to the
+// Response, so it can be removed before they are computed again.
+type derivedState struct {
+	points     []derivedEntry
+	violations []ViolationInfo
+}
+
+// derivedEntry is a stored derived PerformanceDataPoint and the PerformanceDataPoint it replaced, if any.
+type derivedEntry struct {
+	key      performanceDataPointKey
+	replaced *PerformanceDataPoint
+}
+
+// addDerivedPerformanceDataPoint stores a derived or lazy PerformanceDataPoint, which is computed whenever the response
+// is validated.
+func (r *Response) addDerivedPerformanceDataPoint(point *PerformanceDataPoint) {
+	derived := *point
+	r.derivedPerformanceData = append(r.derivedPerformanceData, &derived)
+}
+
+/*
+computeDerivedPerformanceData computes the values of all derived and lazy performance data points and adds them to
+the performance data. The points, messages and violations of the previous computation are removed first, so the
+output always reflects the current raw points, also if the output was already generated before. Derived points that
+can not be added result in status UNKNOWN. They are added even if the Response is finalized, because they are part of
+its output.
+*/
+func (r *Response) computeDerivedPerformanceData() {
+	removed := r.removeDerivedPerformanceData()
+	messages, violations := len(r.outputMessages), len(r.violations)
+	for _, definition := range r.derivedPerformanceData {
+		point := *definition
+		var value float64
+		if point.lazy != nil {
+			var err error
+			if value, err = point.lazy(); err != nil {
+				r.debug("failed to compute lazy performance data point", "metric", point.Metric, "error", err)
+				value = math.NaN()
+			}
+		} else {
+			value = point.derive(PerfData{r.performanceData})
+		}
+		point.Value = value
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			point.SetValueUnknown()
+		}
+		point.derive, point.lazy = nil, nil
+
+		key := performanceDataPointKey{point.Metric, point.Label}
+		var replaced *PerformanceDataPoint
+		if existing, ok := r.performanceData[key]; ok {
+			replaced = &existing
+		}
+		stored, err := r.storePerformanceDataPoint(&point, r.autoCheckThresholds, r.duplicatePolicy)
+		if stored != nil {
+			entry := derivedEntry{key: performanceDataPointKey{stored.Metric, stored.Label}}
+			if entry.key == key {
+				entry.replaced = replaced
+			}
+			r.derived.points = append(r.derived.points, entry)
+		}
+		if err != nil {
+			r.debug("failed to add derived performance data point", "metric", point.Metric, "error", err)
+			r.updateStatus(OutputMessage{
+				Status:  UNKNOWN,
+				Message: "failed to add derived performance data point " + point.name(),
+			})
+		}
+	}
+	for i := messages; i < len(r.outputMessages); i++ {
+		r.outputMessages[i].derived = true
+	}
+	r.derived.violations = append(r.derived.violations, r.violations[violations:]...)
+	if removed {
+		r.recalculateStatusCode()
+	}
+}
+
+// removeDerivedPerformanceData removes the points, messages and violations of the last computation of the derived
+// performance data points. It returns true if messages or violations were removed.
+func (r *Response) removeDerivedPerformanceData() bool {
+	for i := len(r.derived.points) - 1; i >= 0; i-- {
+		entry := r.derived.points[i]
+		if entry.replaced != nil {
+			r.performanceData[entry.key] = *entry.replaced
+		} else {
+			delete(r.performanceData, entry.key)
+		}
+	}
+
+	var messages []OutputMessage
+	for _, message := range r.outputMessages {
+		if !message.derived {
+			messages = append(messages, message)
+		}
+	}
+	removed := len(messages) != len(r.outputMessages)
+	r.outputMessages = messages
+
+	if len(r.derived.violations) > 0 {
+		// a new slice is used, because the violations may be referenced by a ResponseInfo
+		violations := append([]ViolationInfo(nil), r.violations...)
+		for _, violation := range r.derived.violations {
+			for i := len(violations) - 1; i >= 0; i-- {
+				if violations[i] == violation {
+					violations = append(violations[:i], violations[i+1:]...)
+					break
+				}
+			}
+		}
+		r.violations = violations
+		removed = true
+	}
+	r.derived = derivedState{}
+	return removed
+}
@@ -0,0 +1,103 @@
+package monitoringplugin
+
+import (
+	"bytes"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNewDerivedDataPoint(t *testing.T) {
+	r := NewResponse("checked")
+	assert.NoError(t, r.AddPerformanceDataPoint(NewDerivedDataPoint("usage_pct", func(pd PerfData) float64 {
+		return pd.Value("used", "") / pd.Value("total", "") * 100
+	}).SetUnit("%").SetMin(0).SetMax(100).SetThresholds(NewThresholds(nil, 80, nil, 90))))
+	assert.NoError(t, r.AddPerformanceDataPoint(NewDerivedDataPoint("free", func(pd PerfData) float64 {
+		return pd.Value("total", "") - pd.Value("used", "")
+	})))
+	assert.NoError(t, r.AddPerformanceDataPoint(NewDerivedDataPoint("missing", func(pd PerfData) float64 {
+		return pd.Value("missing", "")
+	})))
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("used", 85)))
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("total", 100)))
+
+	info := r.GetInfo()
+	assert.Equal(t, WARNING, info.StatusCode)
+	assert.Equal(t, "'used'=85 'total'=100 'usage_pct'=85%;~:80;~:90;0;100 'free'=15 'missing'=U",
+		r.performanceDataOutput())
+	assert.Len(t, r.GetInfo().PerformanceData, 5)
+
+	r = NewResponse("checked")
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("usage_pct", 1)))
+	assert.NoError(t, r.AddPerformanceDataPoint(NewDerivedDataPoint("usage_pct", func(pd PerfData) float64 {
+		return 1
+	})))
+	assert.Equal(t, UNKNOWN, r.GetInfo().StatusCode)
+}
+
+func TestNewLazyPerformanceDataPoint(t *testing.T) {
+	var retries int
+	r := NewResponse("checked")
+	assert.NoError(t, r.AddPerformanceDataPoint(NewLazyPerformanceDataPoint("retries", func() (float64, error) {
+		return float64(retries), nil
+	}).SetThresholds(NewThresholds(nil, 2, nil, 5))))
+	assert.NoError(t, r.AddPerformanceDataPoint(NewLazyPerformanceDataPoint("failed", func() (float64, error) {
+		return 0, errors.New("not available")
+	})))
+	retries = 3
+
+	assert.Equal(t, WARNING, r.GetInfo().StatusCode)
+	assert.Equal(t, "'retries'=3;~:2;~:5;; 'failed'=U", r.performanceDataOutput())
+}
+
+func TestNewDerivedDataPoint_Recompute(t *testing.T) {
+	r := NewResponse("checked")
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("used", 90)))
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("total", 200)))
+	assert.NoError(t, r.AddPerformanceDataPoint(NewDerivedDataPoint("pct", func(pd PerfData) float64 {
+		return pd.Value("used", "") / pd.Value("total", "") * 100
+	}).SetThresholds(NewThresholds(nil, 40, nil, 60))))
+
+	info := r.GetInfo()
+	assert.Equal(t, WARNING, info.StatusCode)
+	assert.Len(t, info.Violations, 1)
+
+	assert.NoError(t, r.AddOrReplacePerformanceDataPoint(NewPerformanceDataPoint("used", 40)))
+	assert.Equal(t, "OK: checked | 'used'=40 'total'=200 'pct'=20;~:40;~:60;;", r.String())
+	assert.Empty(t, r.Violations())
+	assert.Len(t, info.Violations, 1)
+
+	assert.NoError(t, r.AddOrReplacePerformanceDataPoint(NewPerformanceDataPoint("used", 150)))
+	r.Finalize()
+	output, exitCode := r.Output()
+	assert.Equal(t, "CRITICAL: pct is outside of CRITICAL threshold | 'used'=150 'total'=200 'pct'=75;~:40;~:60;;\n",
+		string(output))
+	assert.Equal(t, 2, exitCode)
+
+	assert.NoError(t, r.SetLateMutationBehavior(LateMutationPanic))
+	assert.NotPanics(t, func() {
+		assert.Equal(t, CRITICAL, r.GetInfo().StatusCode)
+	})
+}
+
+func TestNewLazyPerformanceDataPoint_Reevaluate(t *testing.T) {
+	var retries int
+	r := NewResponse("checked")
+	assert.NoError(t, r.AddPerformanceDataPoint(NewLazyPerformanceDataPoint("retries", func() (float64, error) {
+		return float64(retries), nil
+	}).SetThresholds(NewThresholds(nil, 2, nil, 5))))
+
+	assert.Equal(t, "OK: checked | 'retries'=0;~:2;~:5;;", r.String())
+	retries = 6
+	r.Finalize()
+
+	var buffer bytes.Buffer
+	r.SetOutputWriter(&buffer)
+	var exitCode int
+	r.SetExitFunc(func(code int) {
+		exitCode = code
+	})
+	r.OutputAndExit()
+	assert.Equal(t, "CRITICAL: retries is outside of CRITICAL threshold | 'retries'=6;~:2;~:5;;\n", buffer.String())
+	assert.Equal(t, 2, exitCode)
+}
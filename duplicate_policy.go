@@ -0,0 +1,66 @@
+package monitoringplugin
+
+import (
+	"github.com/pkg/errors"
+	"strconv"
+)
+
+// DuplicatePolicy specifies how the Response behaves if a PerformanceDataPoint with the same metric and label as an
+// already added PerformanceDataPoint is added.
+type DuplicatePolicy int
+
+const (
+	// DuplicateError returns an error and keeps the existing PerformanceDataPoint. This is the default.
+	DuplicateError DuplicatePolicy = iota
+	// DuplicateReplace replaces the existing PerformanceDataPoint, keeping its position in the output.
+	DuplicateReplace
+	// DuplicateSuffix appends an index to the metric of the new PerformanceDataPoint, e.g. "metric_2".
+	DuplicateSuffix
+)
+
+/*
+SetDuplicatePerformanceDataPolicy sets how performance data points with the same metric and label as an already added
+point are handled, which helps retry loops and aggregation code that may legitimately re-emit a metric.
+The default is DuplicateError.
+*/
+func (r *Response) SetDuplicatePerformanceDataPolicy(policy DuplicatePolicy) {
+	r.duplicatePolicy = policy
+}
+
+/*
+AddOrReplacePerformanceDataPoint works like AddPerformanceDataPoint(*PerformanceDataPoint), but replaces an existing
+PerformanceDataPoint with the same metric and label instead of returning an error. Status updates that resulted from
+the thresholds of the replaced point are kept.
+*/
+func (r *Response) AddOrReplacePerformanceDataPoint(point *PerformanceDataPoint) error {
+	return r.addPerformanceDataPoint(point, r.autoCheckThresholds, DuplicateReplace)
+}
+
+/*
+addWithPolicy adds a PerformanceDataPoint to the performanceData map and handles duplicates according to the given
+policy. It returns the stored PerformanceDataPoint, whose metric differs from the given one if an index was appended.
+*/
+func (p *performanceData) addWithPolicy(point *PerformanceDataPoint, policy DuplicatePolicy) (*PerformanceDataPoint,
+	error) {
+	key := performanceDataPointKey{point.Metric, point.Label}
+	existing, ok := (*p)[key]
+	switch {
+	case !ok || policy == DuplicateError:
+		return point, p.add(point)
+	case policy == DuplicateReplace:
+		if err := point.Validate(); err != nil {
+			return nil, errors.Wrap(err, "given performance data point is not valid")
+		}
+		replaced := *point
+		replaced.sequence = existing.sequence
+		(*p)[key] = replaced
+		return &replaced, nil
+	default: // DuplicateSuffix
+		suffixed := *point
+		for i := 2; ok; i++ {
+			suffixed.Metric = point.Metric + "_" + strconv.Itoa(i)
+			_, ok = (*p)[performanceDataPointKey{suffixed.Metric, suffixed.Label}]
+		}
+		return &suffixed, p.add(&suffixed)
+	}
+}
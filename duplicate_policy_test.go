@@ -0,0 +1,32 @@
+package monitoringplugin
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestResponse_AddOrReplacePerformanceDataPoint(t *testing.T) {
+	r := NewResponse("checked")
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("a", 1)))
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("b", 2)))
+	assert.Error(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("a", 3)))
+	assert.NoError(t, r.AddOrReplacePerformanceDataPoint(NewPerformanceDataPoint("a", 3)))
+	assert.NoError(t, r.AddOrReplacePerformanceDataPoint(NewPerformanceDataPoint("c", 4)))
+	assert.Error(t, r.AddOrReplacePerformanceDataPoint(NewPerformanceDataPoint("a", 5).SetMax(1)))
+	assert.Equal(t, "'a'=3 'b'=2 'c'=4", r.performanceDataOutput())
+}
+
+func TestResponse_SetDuplicatePerformanceDataPolicy(t *testing.T) {
+	r := NewResponse("checked")
+	r.SetDuplicatePerformanceDataPolicy(DuplicateSuffix)
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("a", 1)))
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("a", 2)))
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("a", 3).
+		SetThresholds(NewThresholds(nil, 1, nil, 2))))
+	assert.Equal(t, "'a'=1 'a_2'=2 'a_3'=3;~:1;~:2;;", r.performanceDataOutput())
+	assert.Equal(t, "a_3 is outside of CRITICAL threshold", r.outputMessages[0].Message)
+
+	r.SetDuplicatePerformanceDataPolicy(DuplicateReplace)
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("a", 4)))
+	assert.Equal(t, "'a'=4 'a_2'=2 'a_3'=3;~:1;~:2;;", r.performanceDataOutput())
+}
@@ -0,0 +1,84 @@
+package monitoringplugin
+
+import (
+	"time"
+)
+
+// durationUnits are the units of measurement that are used for the supported duration units.
+var durationUnits = map[time.Duration]string{
+	time.Second:      "s",
+	time.Millisecond: "ms",
+	time.Microsecond: "us",
+}
+
+/*
+NewDurationDataPoint creates a new PerformanceDataPoint for a duration, which is rendered in seconds with the unit 's'.
+Thresholds can be given as durations with SetDurationThresholds.
+Usage:
+	PerformanceDataPoint := NewDurationDataPoint("response_time", 1500*time.Millisecond).
+		SetDurationThresholds(nil, time.Second, nil, 2*time.Second)
+	//'response_time'=1.5s;~:1;~:2;;
+*/
+func NewDurationDataPoint(metric string, d time.Duration) *PerformanceDataPoint {
+	return NewDurationDataPointWithUnit(metric, d, time.Second)
+}
+
+/*
+NewDurationDataPointWithUnit creates a new PerformanceDataPoint for a duration, which is rendered in the given unit.
+Supported units are time.Second ('s'), time.Millisecond ('ms') and time.Microsecond ('us'), other units are replaced
+by time.Second.
+Usage:
+	PerformanceDataPoint := NewDurationDataPointWithUnit("response_time", 1500*time.Millisecond, time.Millisecond)
+	//'response_time'=1500ms
+*/
+func NewDurationDataPointWithUnit(metric string, d time.Duration, unit time.Duration) *PerformanceDataPoint {
+	if _, ok := durationUnits[unit]; !ok {
+		unit = time.Second
+	}
+	p := NewPerformanceDataPoint(metric, nil).SetUnit(durationUnits[unit])
+	p.durationUnit = unit
+	p.Value = p.durationValue(d)
+	return p
+}
+
+/*
+SetDurationThresholds sets the thresholds of a duration performance data point (see NewDurationDataPoint). The
+thresholds must be durations or nil and are converted to the unit of the performance data point.
+*/
+func (p *PerformanceDataPoint) SetDurationThresholds(warningMin, warningMax, criticalMin,
+	criticalMax interface{}) *PerformanceDataPoint {
+	p.Thresholds = NewThresholds(p.durationThreshold(warningMin), p.durationThreshold(warningMax),
+		p.durationThreshold(criticalMin), p.durationThreshold(criticalMax))
+	return p
+}
+
+// durationThreshold converts a duration threshold to the unit of the performance data point. Other values are
+// returned unchanged, so they are reported by Validate.
+func (p *PerformanceDataPoint) durationThreshold(threshold interface{}) interface{} {
+	if d, ok := threshold.(time.Duration); ok {
+		return p.durationValue(d)
+	}
+	return threshold
+}
+
+// durationValue converts a duration to the unit of the performance data point.
+func (p *PerformanceDataPoint) durationValue(d time.Duration) float64 {
+	unit := p.durationUnit
+	if unit == 0 {
+		unit = time.Second
+	}
+	return float64(d) / float64(unit)
+}
+
+// validateDuration checks that no duration was passed to a duration performance data point without being converted.
+func (p *PerformanceDataPoint) validateDuration() error {
+	fields := []string{"value", "min", "max", "thresholds", "thresholds", "thresholds", "thresholds"}
+	for i, v := range []interface{}{p.Value, p.Min, p.Max, p.Thresholds.WarningMin, p.Thresholds.WarningMax,
+		p.Thresholds.CriticalMin, p.Thresholds.CriticalMax} {
+		if _, ok := v.(time.Duration); ok {
+			return p.validationError(fields[i], "durations must be set with NewDurationDataPoint or SetDurationThresholds",
+				nil)
+		}
+	}
+	return nil
+}
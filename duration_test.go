@@ -0,0 +1,30 @@
+package monitoringplugin
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestNewDurationDataPoint(t *testing.T) {
+	p := NewDurationDataPoint("response_time", 1500*time.Millisecond).
+		SetDurationThresholds(nil, time.Second, nil, 2*time.Second)
+	assert.NoError(t, p.Validate())
+	assert.Equal(t, "'response_time'=1.5s;~:1;~:2;;", string(p.output(false)))
+
+	p = NewDurationDataPointWithUnit("response_time", 1500*time.Millisecond, time.Millisecond).
+		SetDurationThresholds(nil, 200*time.Millisecond, nil, nil).SetMin(0)
+	assert.Equal(t, "'response_time'=1500ms;~:200;;0;", string(p.output(false)))
+
+	p = NewDurationDataPointWithUnit("response_time", 1500*time.Microsecond, time.Hour)
+	assert.Equal(t, "'response_time'=0.0015s", string(p.output(false)))
+
+	r := NewResponse("checked")
+	assert.NoError(t, r.AddPerformanceDataPoint(NewDurationDataPoint("response_time", 3*time.Second).
+		SetDurationThresholds(nil, time.Second, nil, 2*time.Second)))
+	assert.Equal(t, CRITICAL, r.GetStatusCode())
+
+	assert.Error(t, NewPerformanceDataPoint("response_time", time.Second).Validate())
+	assert.Error(t, NewDurationDataPoint("response_time", time.Second).
+		SetThresholds(NewThresholds(nil, time.Second, nil, nil)).Validate())
+}
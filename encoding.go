@@ -0,0 +1,201 @@
+package monitoringplugin
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"math/big"
+	"strconv"
+	"time"
+)
+
+// encodedNumber is the text representation of a value, min, max or threshold of a PerformanceDataPoint that is used
+// for marshaling. It is marshaled as a number if possible.
+type encodedNumber string
+
+func newEncodedNumber(v interface{}) *encodedNumber {
+	if v == nil {
+		return nil
+	}
+	n := encodedNumber(formatNumber(v))
+	return &n
+}
+
+// value returns the decoded number as int64, *big.Int (if it does not fit in an int64) or float64. If the text is not
+// a number, it is returned as string.
+func (n *encodedNumber) value() interface{} {
+	if n == nil {
+		return nil
+	}
+	if i, err := strconv.ParseInt(string(*n), 10, 64); err == nil {
+		return i
+	}
+	if i, ok := new(big.Int).SetString(string(*n), 10); ok {
+		return i
+	}
+	if f, err := strconv.ParseFloat(string(*n), 64); err == nil {
+		return f
+	}
+	return string(*n)
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (n encodedNumber) MarshalJSON() ([]byte, error) {
+	if _, err := strconv.ParseFloat(string(n), 64); err == nil {
+		return []byte(n), nil
+	}
+	return json.Marshal(string(n))
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (n *encodedNumber) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*n = encodedNumber(text)
+		return nil
+	}
+	var number json.Number
+	if err := json.Unmarshal(data, &number); err != nil {
+		return err
+	}
+	*n = encodedNumber(number)
+	return nil
+}
+
+// MarshalYAML implements the yaml.Marshaler interface.
+func (n encodedNumber) MarshalYAML() (interface{}, error) {
+	return n.value(), nil
+}
+
+// thresholdsEncoding is used to marshal Thresholds. Thresholds that are not set are omitted.
+type thresholdsEncoding struct {
+	WarningMin  *encodedNumber `yaml:"warningMin,omitempty" json:"warningMin,omitempty" xml:"warningMin,omitempty"`
+	WarningMax  *encodedNumber `yaml:"warningMax,omitempty" json:"warningMax,omitempty" xml:"warningMax,omitempty"`
+	CriticalMin *encodedNumber `yaml:"criticalMin,omitempty" json:"criticalMin,omitempty" xml:"criticalMin,omitempty"`
+	CriticalMax *encodedNumber `yaml:"criticalMax,omitempty" json:"criticalMax,omitempty" xml:"criticalMax,omitempty"`
+
+	WarningInside  bool `yaml:"warningInside,omitempty" json:"warningInside,omitempty" xml:"warningInside,omitempty"`
+	CriticalInside bool `yaml:"criticalInside,omitempty" json:"criticalInside,omitempty" xml:"criticalInside,omitempty"`
+}
+
+// performanceDataPointEncoding is used to marshal a PerformanceDataPoint. Min and max are omitted if they are not set,
+// HasMin and HasMax explicitly state whether they are set.
+type performanceDataPointEncoding struct {
+	Metric      string             `yaml:"metric" json:"metric" xml:"metric"`
+	Label       string             `yaml:"label" json:"label" xml:"label"`
+	Value       *encodedNumber     `yaml:"value" json:"value" xml:"value"`
+	Unit        string             `yaml:"unit" json:"unit" xml:"unit"`
+	Thresholds  thresholdsEncoding `yaml:"thresholds" json:"thresholds" xml:"thresholds"`
+	HasMin      bool               `yaml:"has_min" json:"has_min" xml:"has_min"`
+	Min         *encodedNumber     `yaml:"min,omitempty" json:"min,omitempty" xml:"min,omitempty"`
+	HasMax      bool               `yaml:"has_max" json:"has_max" xml:"has_max"`
+	Max         *encodedNumber     `yaml:"max,omitempty" json:"max,omitempty" xml:"max,omitempty"`
+	Counter     bool               `yaml:"counter,omitempty" json:"counter,omitempty" xml:"counter,omitempty"`
+	Timestamp   *time.Time         `yaml:"timestamp,omitempty" json:"timestamp,omitempty" xml:"timestamp,omitempty"`
+	Description string             `yaml:"description,omitempty" json:"description,omitempty" xml:"description,omitempty"`
+}
+
+func (p PerformanceDataPoint) encoding() performanceDataPointEncoding {
+	e := performanceDataPointEncoding{
+		Metric: p.Metric,
+		Label:  p.Label,
+		Value:  newEncodedNumber(p.Value),
+		Unit:   p.Unit,
+		Thresholds: thresholdsEncoding{
+			WarningMin:  newEncodedNumber(p.Thresholds.WarningMin),
+			WarningMax:  newEncodedNumber(p.Thresholds.WarningMax),
+			CriticalMin: newEncodedNumber(p.Thresholds.CriticalMin),
+			CriticalMax: newEncodedNumber(p.Thresholds.CriticalMax),
+
+			WarningInside:  p.Thresholds.WarningInside,
+			CriticalInside: p.Thresholds.CriticalInside,
+		},
+		HasMin:      p.Min != nil,
+		Min:         newEncodedNumber(p.Min),
+		HasMax:      p.Max != nil,
+		Max:         newEncodedNumber(p.Max),
+		Counter:     p.Counter,
+		Description: p.Description,
+	}
+	if !p.Timestamp.IsZero() {
+		timestamp := p.Timestamp
+		e.Timestamp = &timestamp
+	}
+	return e
+}
+
+func (p *PerformanceDataPoint) decode(e performanceDataPointEncoding) {
+	*p = PerformanceDataPoint{
+		Metric:      e.Metric,
+		Label:       e.Label,
+		Value:       e.Value.value(),
+		Unit:        e.Unit,
+		Counter:     e.Counter,
+		Description: e.Description,
+		Thresholds: Thresholds{
+			WarningMin:  e.Thresholds.WarningMin.value(),
+			WarningMax:  e.Thresholds.WarningMax.value(),
+			CriticalMin: e.Thresholds.CriticalMin.value(),
+			CriticalMax: e.Thresholds.CriticalMax.value(),
+
+			WarningInside:  e.Thresholds.WarningInside,
+			CriticalInside: e.Thresholds.CriticalInside,
+		},
+	}
+	if e.HasMin {
+		p.Min = e.Min.value()
+	}
+	if e.HasMax {
+		p.Max = e.Max.value()
+	}
+	if e.Timestamp != nil {
+		p.Timestamp = *e.Timestamp
+	}
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (p PerformanceDataPoint) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.encoding())
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// Numbers are decoded as int64 if possible, otherwise as float64.
+func (p *PerformanceDataPoint) UnmarshalJSON(data []byte) error {
+	var e performanceDataPointEncoding
+	if err := json.Unmarshal(data, &e); err != nil {
+		return err
+	}
+	p.decode(e)
+	return nil
+}
+
+// MarshalYAML implements the yaml.Marshaler interface.
+func (p PerformanceDataPoint) MarshalYAML() (interface{}, error) {
+	return p.encoding(), nil
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface.
+// Numbers are decoded as int64 if possible, otherwise as float64.
+func (p *PerformanceDataPoint) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var e performanceDataPointEncoding
+	if err := unmarshal(&e); err != nil {
+		return err
+	}
+	p.decode(e)
+	return nil
+}
+
+// MarshalXML implements the xml.Marshaler interface.
+func (p PerformanceDataPoint) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(p.encoding(), start)
+}
+
+// UnmarshalXML implements the xml.Unmarshaler interface.
+// Numbers are decoded as int64 if possible, otherwise as float64.
+func (p *PerformanceDataPoint) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var e performanceDataPointEncoding
+	if err := d.DecodeElement(&e, &start); err != nil {
+		return err
+	}
+	p.decode(e)
+	return nil
+}
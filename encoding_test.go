@@ -0,0 +1,134 @@
+package monitoringplugin
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+	"testing"
+	"time"
+)
+
+func newEncodingTestInfo() ResponseInfo {
+	r := NewResponse("checked")
+	r.UpdateStatus(WARNING, "warning")
+	_ = r.AddPerformanceDataPoint(NewPerformanceDataPoint("metric", 10).SetLabel("label").SetUnit("%").
+		SetMin(0).SetMax(100.5).SetThresholds(NewThresholds(nil, 5, nil, 20)))
+	return r.GetInfo()
+}
+
+func assertEncodingRoundTrip(t *testing.T, info, decoded ResponseInfo) {
+	assert.Equal(t, info.StatusCode, decoded.StatusCode)
+	assert.Equal(t, info.RawOutput, decoded.RawOutput)
+	assert.Equal(t, info.Messages, decoded.Messages)
+	if assert.Len(t, decoded.PerformanceData, 1) {
+		point := decoded.PerformanceData[0]
+		assert.Equal(t, PerformanceDataPoint{
+			Metric:     "metric",
+			Label:      "label",
+			Value:      int64(10),
+			Unit:       "%",
+			Thresholds: NewThresholds(nil, int64(5), nil, int64(20)),
+			Min:        int64(0),
+			Max:        100.5,
+		}, point)
+		original := info.PerformanceData[0]
+		assert.Equal(t, string(original.output(false)), string(point.output(false)))
+	}
+}
+
+func TestResponseInfo_JSON(t *testing.T) {
+	info := newEncodingTestInfo()
+	b, err := json.Marshal(info)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), `{"metric":"metric","label":"label","value":10,"unit":"%","thresholds":{"warningMax":5,"criticalMax":20},"has_min":true,"min":0,"has_max":true,"max":100.5}`)
+	var decoded ResponseInfo
+	assert.NoError(t, json.Unmarshal(b, &decoded))
+	assertEncodingRoundTrip(t, info, decoded)
+}
+
+func TestResponseInfo_YAML(t *testing.T) {
+	info := newEncodingTestInfo()
+	b, err := yaml.Marshal(info)
+	assert.NoError(t, err)
+	var decoded ResponseInfo
+	assert.NoError(t, yaml.Unmarshal(b, &decoded))
+	assertEncodingRoundTrip(t, info, decoded)
+}
+
+func TestResponseInfo_XML(t *testing.T) {
+	info := newEncodingTestInfo()
+	b, err := xml.Marshal(info)
+	assert.NoError(t, err)
+	var decoded ResponseInfo
+	assert.NoError(t, xml.Unmarshal(b, &decoded))
+	assertEncodingRoundTrip(t, info, decoded)
+}
+
+func TestPerformanceDataPoint_UnmarshalJSONWithoutMinMax(t *testing.T) {
+	var point PerformanceDataPoint
+	assert.NoError(t, json.Unmarshal([]byte(`{"metric":"metric","value":1.5}`), &point))
+	assert.Equal(t, PerformanceDataPoint{Metric: "metric", Value: 1.5}, point)
+}
+
+func TestPerformanceDataPoint_Counter_Encoding(t *testing.T) {
+	point := NewPerformanceDataPoint("ifInOctets", 123).SetCounter()
+	b, err := json.Marshal(point)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), `"counter":true`)
+	var decoded PerformanceDataPoint
+	assert.NoError(t, json.Unmarshal(b, &decoded))
+	assert.Equal(t, PerformanceDataPoint{Metric: "ifInOctets", Value: int64(123), Unit: "c", Counter: true}, decoded)
+
+	b, err = json.Marshal(NewPerformanceDataPoint("metric", 1))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(b), "counter")
+}
+
+func TestPerformanceDataPoint_Timestamp_Encoding(t *testing.T) {
+	timestamp := time.Date(2020, 9, 13, 12, 26, 40, 0, time.UTC)
+	point := NewPerformanceDataPoint("metric", 1).SetTimestamp(timestamp)
+
+	b, err := json.Marshal(point)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), `"timestamp":"2020-09-13T12:26:40Z"`)
+	var decoded PerformanceDataPoint
+	assert.NoError(t, json.Unmarshal(b, &decoded))
+	assert.True(t, timestamp.Equal(decoded.Timestamp))
+
+	b, err = yaml.Marshal(point)
+	assert.NoError(t, err)
+	decoded = PerformanceDataPoint{}
+	assert.NoError(t, yaml.Unmarshal(b, &decoded))
+	assert.True(t, timestamp.Equal(decoded.Timestamp))
+
+	b, err = json.Marshal(NewPerformanceDataPoint("metric", 1))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(b), "timestamp")
+}
+
+func TestPerformanceDataPoint_Description_Encoding(t *testing.T) {
+	r := NewResponse("checked")
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("in_octets", 1).
+		SetDescription("received bytes")))
+	info := r.GetInfo()
+	assert.Equal(t, "received bytes", info.PerformanceData[0].Description)
+	assert.Equal(t, "'in_octets'=1", r.performanceDataOutput())
+
+	b, err := json.Marshal(info)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), `"description":"received bytes"`)
+	var decoded ResponseInfo
+	assert.NoError(t, json.Unmarshal(b, &decoded))
+	assert.Equal(t, "received bytes", decoded.PerformanceData[0].Description)
+
+	b, err = yaml.Marshal(info)
+	assert.NoError(t, err)
+	decoded = ResponseInfo{}
+	assert.NoError(t, yaml.Unmarshal(b, &decoded))
+	assert.Equal(t, "received bytes", decoded.PerformanceData[0].Description)
+
+	b, err = json.Marshal(NewPerformanceDataPoint("metric", 1))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(b), "description")
+}
@@ -0,0 +1,83 @@
+package monitoringplugin
+
+import (
+	"fmt"
+	"strings"
+)
+
+/*
+SetErrorChainDepth sets how many wrapped errors are rendered by UpdateStatusOnError if includeErrorMessage is true.
+Instead of one long error string, the error chain is rendered as a readable "caused by" sequence. The last rendered
+error contains the complete remaining error text. A value of 0 or less disables the rendering, which is the default.
+Example:
+	Response.SetErrorChainDepth(3)
+	//CRITICAL: API request failed (error: query failed, caused by: read response, caused by: connection reset)
+*/
+func (r *Response) SetErrorChainDepth(depth int) {
+	r.errorChainDepth = depth
+}
+
+/*
+IncludeErrorType activates or deactivates appending the type name of the innermost error to errors rendered by
+UpdateStatusOnError, e.g. "connection refused [*net.OpError]", which makes triage easier.
+*/
+func (r *Response) IncludeErrorType(b bool) {
+	r.includeErrorType = b
+}
+
+// errorText returns the text of an error as it is rendered in output messages.
+func (r *Response) errorText(err error) string {
+	text := err.Error()
+	if r.errorChainDepth > 0 {
+		text = strings.Join(errorChain(err, r.errorChainDepth), ", caused by: ")
+	}
+	if r.includeErrorType {
+		text += fmt.Sprintf(" [%T]", rootCause(err))
+	}
+	return text
+}
+
+/*
+errorChain returns the messages of the errors in the chain of wrapped errors, without the messages of their causes.
+Errors that do not add a message (e.g. errors that only add a stack trace) are skipped. If the chain is longer than
+depth, the last element contains the complete remaining error text.
+*/
+func errorChain(err error, depth int) []string {
+	var chain []string
+	for err != nil {
+		text := err.Error()
+		cause := unwrapError(err)
+		if len(chain) == depth-1 || cause == nil {
+			return append(chain, text)
+		}
+		causeText := cause.Error()
+		if text != causeText {
+			chain = append(chain, strings.TrimSuffix(strings.TrimSuffix(text, causeText), ": "))
+		}
+		err = cause
+	}
+	return chain
+}
+
+// unwrapError returns the error wrapped by err, supporting both the standard library and github.com/pkg/errors.
+func unwrapError(err error) error {
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		return e.Unwrap()
+	case interface{ Cause() error }:
+		return e.Cause()
+	default:
+		return nil
+	}
+}
+
+// rootCause returns the innermost error of the chain of wrapped errors.
+func rootCause(err error) error {
+	for {
+		cause := unwrapError(err)
+		if cause == nil {
+			return err
+		}
+		err = cause
+	}
+}
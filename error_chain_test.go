@@ -0,0 +1,49 @@
+package monitoringplugin
+
+import (
+	stderrors "errors"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestResponse_SetErrorChainDepth(t *testing.T) {
+	err := fmt.Errorf("query failed: %w", errors.Wrap(stderrors.New("connection reset"), "read response"))
+
+	r := NewResponse("checked")
+	r.UpdateStatusOnError(err, CRITICAL, "API request failed", true)
+	assert.Equal(t, "API request failed (error: query failed: read response: connection reset)", r.Messages()[0].Message)
+
+	r = NewResponse("checked")
+	r.SetErrorChainDepth(5)
+	r.UpdateStatusOnError(err, CRITICAL, "API request failed", true)
+	r.UpdateStatusOnError(err, CRITICAL, "", true)
+	assert.Equal(t, []OutputMessage{
+		{Status: CRITICAL, Message: "API request failed (error: query failed, caused by: read response, caused by: connection reset)"},
+		{Status: CRITICAL, Message: "query failed, caused by: read response, caused by: connection reset"},
+	}, r.Messages())
+
+	r = NewResponse("checked")
+	r.SetErrorChainDepth(2)
+	r.UpdateStatusOnError(err, CRITICAL, "", true)
+	assert.Equal(t, "query failed, caused by: read response: connection reset", r.Messages()[0].Message)
+}
+
+type testTimeoutError struct{}
+
+func (testTimeoutError) Error() string {
+	return "i/o timeout"
+}
+
+func TestResponse_IncludeErrorType(t *testing.T) {
+	err := fmt.Errorf("open /etc/check.conf: %w", testTimeoutError{})
+
+	r := NewResponse("checked")
+	r.IncludeErrorType(true)
+	r.UpdateStatusOnError(errors.Wrap(err, "failed to read config"), UNKNOWN, "", true)
+	r.UpdateStatusOnError(err, UNKNOWN, "", false)
+	assert.Equal(t, []OutputMessage{
+		{Status: UNKNOWN, Message: "failed to read config: open /etc/check.conf: i/o timeout [monitoringplugin.testTimeoutError]"},
+	}, r.Messages())
+}
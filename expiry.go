@@ -0,0 +1,77 @@
+package monitoringplugin
+
+import (
+	"github.com/pkg/errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+CheckExpiry checks how much time is left until the given expiry date and updates the status of the Response.
+The status is set to CRITICAL if less than critBefore is left, to WARNING if less than warnBefore is left, otherwise it
+stays OK. A humanized message (e.g. "certificate expires in 12d 3h") and a PerformanceDataPoint containing the remaining
+seconds are added to the response.
+Usage:
+	err := Response.CheckExpiry("certificate", cert.NotAfter, 30*24*time.Hour, 7*24*time.Hour)
+	if err != nil {
+		...
+	}
+*/
+func (r *Response) CheckExpiry(name string, expires time.Time, warnBefore, critBefore time.Duration) error {
+	remaining := time.Until(expires)
+
+	point := NewPerformanceDataPoint(name, int64(remaining/time.Second)).
+		SetUnit("s").
+		SetThresholds(NewThresholds(int64(warnBefore/time.Second), nil, int64(critBefore/time.Second), nil))
+	// the thresholds are not checked, the status is updated with a humanized message below
+	if err := r.addPerformanceDataPoint(point, false, r.duplicatePolicy); err != nil {
+		return errors.Wrap(err, "failed to add performance data point")
+	}
+
+	status := OK
+	switch {
+	case remaining < critBefore:
+		status = CRITICAL
+	case remaining < warnBefore:
+		status = WARNING
+	}
+
+	var msg string
+	if remaining < 0 {
+		msg = name + " expired " + humanizeDuration(-remaining) + " ago"
+	} else {
+		msg = name + " expires in " + humanizeDuration(remaining)
+	}
+	r.UpdateStatus(status, msg)
+	return nil
+}
+
+// humanizeDuration formats a duration using its two most significant units, e.g. "3d 4h" or "5m 10s".
+func humanizeDuration(d time.Duration) string {
+	units := []struct {
+		suffix string
+		length time.Duration
+	}{
+		{"d", 24 * time.Hour},
+		{"h", time.Hour},
+		{"m", time.Minute},
+		{"s", time.Second},
+	}
+
+	var parts []string
+	for _, unit := range units {
+		if len(parts) == 2 {
+			break
+		}
+		n := d / unit.length
+		d -= n * unit.length
+		if n > 0 || len(parts) > 0 {
+			parts = append(parts, strconv.FormatInt(int64(n), 10)+unit.suffix)
+		}
+	}
+	if len(parts) == 0 {
+		return "0s"
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,44 @@
+package monitoringplugin
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResponse_CheckExpiry(t *testing.T) {
+	r := NewResponse("")
+	assert.NoError(t, r.CheckExpiry("license", time.Now().Add(100*24*time.Hour+time.Minute), 30*24*time.Hour, 7*24*time.Hour))
+	assert.Equal(t, OK, r.statusCode)
+	assert.Equal(t, "license expires in 100d 0h", r.outputMessages[0].Message)
+
+	assert.NoError(t, r.CheckExpiry("certificate", time.Now().Add(10*24*time.Hour+time.Minute), 30*24*time.Hour, 7*24*time.Hour))
+	assert.Equal(t, WARNING, r.statusCode)
+
+	assert.NoError(t, r.CheckExpiry("domain", time.Now().Add(-2*time.Hour), 30*24*time.Hour, 7*24*time.Hour))
+	assert.Equal(t, CRITICAL, r.statusCode)
+	assert.Equal(t, "domain expired 2h 0m ago", r.outputMessages[2].Message)
+	assert.Len(t, r.performanceData, 3)
+
+	assert.Error(t, r.CheckExpiry("domain", time.Now(), time.Hour, time.Minute))
+}
+
+func TestResponse_CheckExpiryPipeline(t *testing.T) {
+	r := NewResponse("checked")
+	r.SetMetricPrefix("tls_")
+	assert.NoError(t, r.EnablePerformanceDataStreaming())
+	assert.NoError(t, r.CheckExpiry("cert", time.Now().Add(100*24*time.Hour+time.Minute), 30*24*time.Hour, time.Hour))
+	assert.Equal(t, 1, strings.Count(r.String(), " | "))
+	assert.Contains(t, r.String(), "'tls_cert'=")
+
+	r.Finalize()
+	assert.Error(t, r.CheckExpiry("domain", time.Now(), time.Hour, time.Minute))
+}
+
+func TestHumanizeDuration(t *testing.T) {
+	assert.Equal(t, "0s", humanizeDuration(0))
+	assert.Equal(t, "42s", humanizeDuration(42*time.Second))
+	assert.Equal(t, "5m 10s", humanizeDuration(5*time.Minute+10*time.Second))
+	assert.Equal(t, "3d 4h", humanizeDuration(3*24*time.Hour+4*time.Hour+20*time.Minute))
+}
@@ -0,0 +1,74 @@
+package monitoringplugin
+
+import (
+	"github.com/pkg/errors"
+	"sync/atomic"
+)
+
+// ErrFinalized is returned if a finalized Response is modified (see Response.Finalize()).
+var ErrFinalized = errors.New("response is already finalized")
+
+// LateMutationBehavior specifies how a finalized Response reacts to modifications.
+type LateMutationBehavior int
+
+const (
+	// LateMutationError ignores the modification. AddPerformanceDataPoint returns ErrFinalized, status updates and
+	// added sub-checks are logged with level warn (see Response.SetLogger), because they must not affect the output of
+	// the check plugin.
+	LateMutationError LateMutationBehavior = iota + 1
+	// LateMutationPanic panics with ErrFinalized.
+	LateMutationPanic
+)
+
+/*
+Finalize freezes the Response. Afterwards, status updates, added sub-checks and added performance data points are
+rejected according to the late mutation behavior (see SetLateMutationBehavior(LateMutationBehavior)). This catches
+goroutines that keep modifying the Response after its output was already rendered. Derived and lazy performance data
+points are computed and the sub-checks are aggregated a last time, later changes to the attached sub-checks do not
+affect the Response. OutputAndExit() finalizes the Response automatically, Reset() unfreezes it.
+*/
+func (r *Response) Finalize() {
+	if r.IsFinalized() {
+		return
+	}
+	r.computeDerivedPerformanceData()
+	r.aggregateSubChecks()
+	r.subChecks = cloneSubChecks(r.subChecks)
+	atomic.StoreInt32(&r.finalized, 1)
+}
+
+// IsFinalized checks if the Response was finalized.
+func (r *Response) IsFinalized() bool {
+	return atomic.LoadInt32(&r.finalized) == 1
+}
+
+// SetLateMutationBehavior sets how a finalized Response reacts to modifications. The default is LateMutationError.
+func (r *Response) SetLateMutationBehavior(behavior LateMutationBehavior) error {
+	if behavior != LateMutationError && behavior != LateMutationPanic {
+		return errors.New("invalid late mutation behavior")
+	}
+	r.lateMutationBehavior = behavior
+	return nil
+}
+
+// checkFinalized returns ErrFinalized (or panics, depending on the late mutation behavior) if the Response was
+// finalized or a signal is handled (see HandleSignals(...os.Signal)).
+func (r *Response) checkFinalized() error {
+	if !r.IsFinalized() && !r.handlingSignal() {
+		return nil
+	}
+	if r.lateMutationBehavior == LateMutationPanic {
+		panic(ErrFinalized)
+	}
+	return ErrFinalized
+}
+
+// checkFinalizedStatusUpdate checks if a status update is allowed and logs rejected updates.
+func (r *Response) checkFinalizedStatusUpdate(message OutputMessage) bool {
+	if err := r.checkFinalized(); err != nil {
+		r.warn("status update after finalize ignored", "status", message.Status, "message", message.Message,
+			"error", err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,78 @@
+package monitoringplugin
+
+import (
+	"bytes"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"testing"
+)
+
+func TestResponse_Finalize(t *testing.T) {
+	r := NewResponse("checked")
+	r.UpdateStatus(WARNING, "disk almost full")
+	r.Finalize()
+	assert.True(t, r.IsFinalized())
+
+	r.UpdateStatus(CRITICAL, "late update")
+	r.UpdateStatusKV(CRITICAL, "late update", "key", "value")
+	err := r.AddPerformanceDataPoint(NewPerformanceDataPoint("metric", 1))
+	assert.Error(t, err)
+	assert.Equal(t, ErrFinalized, errors.Cause(err))
+	assert.Equal(t, "WARNING: disk almost full", r.String())
+
+	r.Reset()
+	assert.False(t, r.IsFinalized())
+	r.UpdateStatus(CRITICAL, "disk full")
+	assert.Equal(t, CRITICAL, r.GetStatusCode())
+}
+
+func TestResponse_SetLateMutationBehavior(t *testing.T) {
+	r := NewResponse("checked")
+	assert.Error(t, r.SetLateMutationBehavior(LateMutationBehavior(0)))
+	assert.NoError(t, r.SetLateMutationBehavior(LateMutationPanic))
+	r.Finalize()
+	assert.PanicsWithValue(t, ErrFinalized, func() {
+		r.UpdateStatus(CRITICAL, "late update")
+	})
+	assert.PanicsWithValue(t, ErrFinalized, func() {
+		_ = r.AddPerformanceDataPoint(NewPerformanceDataPoint("metric", 1))
+	})
+}
+
+func TestResponse_OutputAndExit_Finalizes(t *testing.T) {
+	var buffer bytes.Buffer
+	r := NewResponse("checked", WithOutputWriter(&buffer), WithExitFunc(func(int) {}))
+	sub := NewSubCheck()
+	sub.UpdateStatus(WARNING, "partition is almost full")
+	r.AddSubCheck("partition1", sub)
+	r.OutputAndExit()
+	assert.True(t, r.IsFinalized())
+	assert.Equal(t, "WARNING\n\\_ [WARNING] partition1\n    partition is almost full\n", buffer.String())
+
+	r.UpdateStatus(CRITICAL, "late update")
+	assert.Equal(t, WARNING, r.GetStatusCode())
+}
+
+func TestResponse_Finalize_Freezes(t *testing.T) {
+	var logs bytes.Buffer
+	r := NewResponse("checked")
+	r.SetLogger(slog.New(slog.NewTextHandler(&logs, nil)))
+	calls := 0
+	assert.NoError(t, r.AddPerformanceDataPoint(NewLazyPerformanceDataPoint("calls", func() (float64, error) {
+		calls++
+		return float64(calls), nil
+	})))
+	sub := NewSubCheck()
+	r.AddSubCheck("partition1", sub)
+	r.Finalize()
+
+	sub.UpdateStatus(CRITICAL, "partition is full")
+	r.AddSubCheck("partition2", sub)
+	r.UpdateStatus(CRITICAL, "late update")
+	assert.Equal(t, "OK: checked\n\\_ [OK] partition1 | 'calls'=1", r.String())
+	assert.Equal(t, "OK: checked\n\\_ [OK] partition1 | 'calls'=1", r.String())
+	assert.Equal(t, OK, r.GetStatusCode())
+	assert.Contains(t, logs.String(), "level=WARN msg=\"sub-check added after finalize ignored\"")
+	assert.Contains(t, logs.String(), "level=WARN msg=\"status update after finalize ignored\"")
+}
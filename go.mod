@@ -1,8 +1,14 @@
-module github.com/inexio/go-monitoringplugin
+module github.com/inexio/go-monitoringplugin/v3
 
-go 1.14
+go 1.21
 
 require (
 	github.com/pkg/errors v0.8.1
 	github.com/stretchr/testify v1.6.1
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
 )
@@ -0,0 +1,119 @@
+package monitoringplugin
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// defaultQuantiles are the quantiles that are emitted by a Histogram if no other quantiles are set.
+var defaultQuantiles = []float64{0.5, 0.9, 0.99}
+
+/*
+Histogram collects observed samples, e.g. latencies of several requests, and expands them into a set of performance
+data points with consistent naming: one point per quantile ("<metric>_p50", "<metric>_p90", "<metric>_p99" by
+default), the maximum ("<metric>_max") and the number of samples ("<metric>_count").
+Usage:
+	histogram := NewHistogram("response_time").SetUnit("s").
+		SetQuantileThresholds(0.99, NewThresholds(nil, 0.5, nil, 1))
+	for _, latency := range latencies {
+		histogram.Observe(latency)
+	}
+	err := Response.AddHistogram(histogram)
+*/
+type Histogram struct {
+	metric     string
+	unit       string
+	samples    []float64
+	quantiles  []float64
+	thresholds map[float64]Thresholds
+}
+
+// NewHistogram creates a new Histogram. The metric is used as prefix for the names of the emitted data points.
+func NewHistogram(metric string) *Histogram {
+	return &Histogram{
+		metric:     metric,
+		quantiles:  defaultQuantiles,
+		thresholds: make(map[float64]Thresholds),
+	}
+}
+
+// SetUnit sets the unit of the quantile and maximum data points. The count data point has no unit.
+func (h *Histogram) SetUnit(unit string) *Histogram {
+	h.unit = unit
+	return h
+}
+
+// SetQuantiles sets the quantiles (between 0 and 1) that are emitted. The default is 0.5, 0.9 and 0.99.
+func (h *Histogram) SetQuantiles(quantiles ...float64) *Histogram {
+	h.quantiles = quantiles
+	return h
+}
+
+// SetQuantileThresholds sets the thresholds of the data point of the given quantile.
+func (h *Histogram) SetQuantileThresholds(quantile float64, thresholds Thresholds) *Histogram {
+	h.thresholds[quantile] = thresholds
+	return h
+}
+
+// Observe adds samples to the Histogram.
+func (h *Histogram) Observe(samples ...float64) *Histogram {
+	h.samples = append(h.samples, samples...)
+	return h
+}
+
+// DataPoints returns the performance data points of the Histogram. If no samples were observed, the values of the
+// quantile and maximum data points are unknown ('U').
+func (h *Histogram) DataPoints() []*PerformanceDataPoint {
+	samples := append([]float64(nil), h.samples...)
+	sort.Float64s(samples)
+
+	var points []*PerformanceDataPoint
+	for _, quantile := range h.quantiles {
+		point := NewPerformanceDataPoint(h.metric+"_p"+quantileName(quantile), quantileValue(samples, quantile)).
+			SetUnit(h.unit)
+		if thresholds, ok := h.thresholds[quantile]; ok {
+			point.SetThresholds(thresholds)
+		}
+		points = append(points, point)
+	}
+	points = append(points,
+		NewPerformanceDataPoint(h.metric+"_max", quantileValue(samples, 1)).SetUnit(h.unit),
+		NewPerformanceDataPoint(h.metric+"_count", len(samples)).SetMin(0),
+	)
+	for _, point := range points {
+		if value, ok := point.Value.(float64); ok && math.IsNaN(value) {
+			point.SetValueUnknown()
+		}
+	}
+	return points
+}
+
+// quantileName returns the quantile as percentage with at most two decimal places, e.g. "99.9" for 0.999. The
+// percentage is rounded, because 0.29*100 is 28.999999999999996 in floating point arithmetic.
+func quantileName(quantile float64) string {
+	name := strings.TrimRight(strconv.FormatFloat(quantile*100, 'f', 2, 64), "0")
+	return strings.TrimSuffix(name, ".")
+}
+
+// quantileValue returns the quantile of the sorted samples using the nearest-rank method. If there are no samples,
+// NaN is returned.
+func quantileValue(samples []float64, quantile float64) float64 {
+	if len(samples) == 0 {
+		return math.NaN()
+	}
+	rank := int(math.Ceil(quantile * float64(len(samples))))
+	if rank < 1 {
+		rank = 1
+	} else if rank > len(samples) {
+		rank = len(samples)
+	}
+	return samples[rank-1]
+}
+
+// AddHistogram adds the performance data points of the Histogram like
+// AddPerformanceDataPoints(...*PerformanceDataPoint).
+func (r *Response) AddHistogram(h *Histogram) error {
+	return r.AddPerformanceDataPoints(h.DataPoints()...)
+}
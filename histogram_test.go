@@ -0,0 +1,33 @@
+package monitoringplugin
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestHistogram(t *testing.T) {
+	histogram := NewHistogram("response_time").SetUnit("s").
+		SetQuantileThresholds(0.99, NewThresholds(nil, 0.5, nil, 1))
+	for i := 1; i <= 100; i++ {
+		histogram.Observe(float64(i) / 100)
+	}
+
+	r := NewResponse("checked")
+	assert.NoError(t, r.AddHistogram(histogram))
+	assert.Equal(t, WARNING, r.GetStatusCode())
+	assert.Equal(t, "'response_time_p50'=0.5s 'response_time_p90'=0.9s 'response_time_p99'=0.99s;~:0.5;~:1;; "+
+		"'response_time_max'=1s 'response_time_count'=100;;;0;", r.performanceDataOutput())
+
+	var output []string
+	for _, point := range NewHistogram("latency").SetQuantiles(0.999).DataPoints() {
+		output = append(output, string(point.output(false)))
+	}
+	assert.Equal(t, []string{"'latency_p99.9'=U", "'latency_max'=U", "'latency_count'=0;;;0;"}, output)
+}
+
+func TestQuantileName(t *testing.T) {
+	tests := map[float64]string{0.5: "50", 0.29: "29", 0.999: "99.9", 0.9995: "99.95", 1: "100", 0.07: "7", 0: "0"}
+	for quantile, expected := range tests {
+		assert.Equal(t, expected, quantileName(quantile), quantile)
+	}
+}
@@ -0,0 +1,112 @@
+package monitoringplugin
+
+import (
+	"encoding/json"
+	"strings"
+	"unicode"
+)
+
+// LabelEncoding is the syntax that is used to encode the label of a PerformanceDataPoint in the performance data
+// output.
+type LabelEncoding int
+
+const (
+	// LabelEncodingSuffix appends the label to the metric: 'metric_label'. This is the default.
+	LabelEncodingSuffix LabelEncoding = iota
+	// LabelEncodingJSON encodes metric and label as JSON object: '{"metric":"metric","label":"label"}'.
+	LabelEncodingJSON
+	// LabelEncodingPrometheus encodes the label in Prometheus syntax: 'metric{label="label"}'.
+	LabelEncodingPrometheus
+	// LabelEncodingInflux encodes the label as InfluxDB line protocol tag: 'metric,label=label'.
+	LabelEncodingInflux
+)
+
+// defaultLabelKey is the key of the label in the Prometheus and Influx label encodings.
+const defaultLabelKey = "label"
+
+/*
+SetLabelEncoding sets the syntax that is used to encode the labels of performance data points, so downstream
+pipelines that parse performance data into time series databases get labels in their native syntax. The key of the
+label in the Prometheus and Influx encodings can be set with SetLabelKey(string).
+Example:
+	Response.SetLabelEncoding(LabelEncodingPrometheus)
+	Response.SetLabelKey("iface")
+	//'in_octets{iface="eth0"}'=123
+*/
+func (r *Response) SetLabelEncoding(encoding LabelEncoding) {
+	r.labelEncoding = encoding
+}
+
+// SetLabelKey sets the key of the label in the Prometheus and Influx label encodings. The default is "label".
+func (r *Response) SetLabelKey(key string) {
+	r.labelKey = key
+}
+
+/*
+SetCompactLabelQuoting activates or deactivates the compact quoting mode. By default, every label of the performance
+data is wrapped in single quotes. In compact mode, only labels that contain whitespace or special characters are
+quoted, as the Monitoring Plugins Development Guidelines allow, which makes the performance data shorter.
+Example:
+	Response.SetCompactLabelQuoting(true)
+	//load=1.5 'used space'=10GB
+*/
+func (r *Response) SetCompactLabelQuoting(b bool) {
+	r.compactLabelQuoting = b
+}
+
+// requiresQuoting returns whether a performance data label must be quoted, i.e. whether it is empty or contains other
+// characters than letters, digits and "_-./:%".
+func requiresQuoting(name string) bool {
+	if name == "" {
+		return true
+	}
+	for _, c := range name {
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) && !strings.ContainsRune("_-./:%", c) {
+			return true
+		}
+	}
+	return false
+}
+
+// pointOutput returns the PerformanceDataPoint in the output format, using the label encoding of the Response.
+func (r *Response) pointOutput(point *PerformanceDataPoint) []byte {
+	key := r.labelKey
+	if key == "" {
+		key = defaultLabelKey
+	}
+	if r.hasMaxPrecision && point.precision == nil {
+		limited := *point
+		limited.maxPrecision = &r.maxPrecision
+		point = &limited
+	}
+	if r.compactLabelQuoting {
+		compact := *point
+		compact.compactQuoting = true
+		point = &compact
+	}
+	return point.encode(r.labelEncoding, key)
+}
+
+// encodedName returns the metric and label of the PerformanceDataPoint in the given label encoding.
+func (p *PerformanceDataPoint) encodedName(encoding LabelEncoding, key string) string {
+	if encoding == LabelEncodingJSON {
+		jsonKey, _ := json.Marshal(performanceDataPointKey{
+			Metric: p.Metric,
+			Label:  p.Label,
+		})
+		return string(jsonKey)
+	}
+	if p.Label == "" {
+		return p.Metric
+	}
+	switch encoding {
+	case LabelEncodingPrometheus:
+		replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+		return p.Metric + "{" + key + `="` + replacer.Replace(p.Label) + `"}`
+	case LabelEncodingInflux:
+		replacer := strings.NewReplacer(",", `\,`, " ", `\ `, "=", `\=`)
+		return p.Metric + "," + key + "=" + replacer.Replace(p.Label)
+	default:
+		return p.Metric + "_" + p.Label
+	}
+}
@@ -0,0 +1,43 @@
+package monitoringplugin
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestResponse_SetLabelEncoding(t *testing.T) {
+	r := NewResponse("checked")
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("in_octets", 123).SetLabel("eth 0,1")))
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("uptime", 5)))
+	assert.Equal(t, "'in_octets_eth 0,1'=123 'uptime'=5", r.performanceDataOutput())
+
+	r.SetLabelEncoding(LabelEncodingJSON)
+	assert.Equal(t, `'{"metric":"in_octets","label":"eth 0,1"}'=123 '{"metric":"uptime"}'=5`, r.performanceDataOutput())
+
+	r.SetLabelEncoding(LabelEncodingPrometheus)
+	assert.Equal(t, `'in_octets{label="eth 0,1"}'=123 'uptime'=5`, r.performanceDataOutput())
+
+	r.SetLabelKey("iface")
+	assert.Equal(t, `'in_octets{iface="eth 0,1"}'=123 'uptime'=5`, r.performanceDataOutput())
+
+	r.SetLabelEncoding(LabelEncodingInflux)
+	assert.Equal(t, `'in_octets,iface=eth\ 0\,1'=123 'uptime'=5`, r.performanceDataOutput())
+}
+
+func TestResponse_SetCompactLabelQuoting(t *testing.T) {
+	r := NewResponse("checked")
+	r.SetCompactLabelQuoting(true)
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("load", 1.5)))
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("used space", 10).SetUnit("GB")))
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("disk", 20).SetLabel("/var/log")))
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("if", 30).SetLabel("Gi0/1 uplink")))
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("ping", 40).SetLabel("host\tname")))
+	assert.Equal(t, "OK: checked | load=1.5 'used space'=10GB disk_/var/log=20 'if_Gi0/1 uplink'=30 'ping_host\tname'=40",
+		r.GetInfo().RawOutput)
+
+	r.SetLabelEncoding(LabelEncodingPrometheus)
+	assert.Contains(t, r.GetInfo().RawOutput, `'disk{label="/var/log"}'=20`)
+
+	r.SetCompactLabelQuoting(false)
+	assert.Contains(t, r.GetInfo().RawOutput, "| 'load'=1.5 ")
+}
@@ -0,0 +1,106 @@
+package monitoringplugin
+
+import (
+	"bytes"
+	"github.com/pkg/errors"
+	"io"
+	"strings"
+)
+
+/*
+AttachLongOutput reads from the given reader and attaches the last maxBytes bytes to the long output of the response.
+The long output is printed after the output messages. If the excerpt starts in the middle of a line, the incomplete
+line is dropped. Invalid characters are removed or replaced when the response is validated, depending on the invalid
+character behavior. With InvalidCharacterCallback, the excerpt is passed to the callback and dropped if the callback
+does not keep it. The other behaviors remove the invalid characters.
+Usage:
+	f, err := os.Open("/var/log/app.log")
+	if err != nil {
+		...
+	}
+	defer f.Close()
+	err = Response.AttachLongOutput(f, 2048)
+	if err != nil {
+		...
+	}
+*/
+func (r *Response) AttachLongOutput(reader io.Reader, maxBytes int) error {
+	if maxBytes <= 0 {
+		return errors.New("max bytes must be greater than 0")
+	}
+
+	tail := tailWriter{maxBytes: maxBytes}
+	if _, err := io.Copy(&tail, reader); err != nil {
+		return errors.Wrap(err, "failed to read long output")
+	}
+
+	excerpt := tail.buffer
+	if tail.truncated {
+		if i := bytes.IndexByte(excerpt, '\n'); i != -1 {
+			excerpt = excerpt[i+1:]
+		}
+	}
+	text := strings.TrimRight(strings.ToValidUTF8(string(excerpt), ""), "\r\n")
+	if text == "" {
+		return nil
+	}
+	r.longOutput = append(r.longOutput, text)
+	return nil
+}
+
+// tailWriter is an io.Writer that only keeps the last maxBytes bytes that are written to it.
+type tailWriter struct {
+	buffer    []byte
+	maxBytes  int
+	truncated bool
+}
+
+func (t *tailWriter) Write(p []byte) (int, error) {
+	t.buffer = append(t.buffer, p...)
+	if overflow := len(t.buffer) - t.maxBytes; overflow > 0 {
+		t.buffer = append(t.buffer[:0], t.buffer[overflow:]...)
+		t.truncated = true
+	}
+	return len(p), nil
+}
+
+// validateLongOutput handles invalid characters in the summary and long output.
+// Only removing or replacing invalid characters and the callback are supported there.
+func (r *Response) validateLongOutput() {
+	r.summary = r.removeInvalidCharacters(r.summary)
+	var longOutput []string
+	for _, text := range r.longOutput {
+		if text = r.removeInvalidCharacters(text); text != "" {
+			longOutput = append(longOutput, text)
+		}
+	}
+	r.longOutput = longOutput
+}
+
+// removeInvalidCharacters replaces or removes invalid characters in a text that is not an output message, using the
+// current status for the callback (see sanitizeMessage(OutputMessage)).
+func (r *Response) removeInvalidCharacters(text string) string {
+	return r.sanitizeMessage(OutputMessage{Status: r.statusCode, Message: text})
+}
+
+// sanitizeMessage returns the text of the message without invalid characters. With InvalidCharacterCallback, the
+// message is passed to the callback and an empty string is returned if the callback does not keep it. Invalid
+// characters are replaced with InvalidCharacterReplace and removed with all other behaviors.
+func (r *Response) sanitizeMessage(message OutputMessage) string {
+	if !strings.Contains(message.Message, "|") {
+		return message.Message
+	}
+	switch r.invalidCharacterBehaviour {
+	case InvalidCharacterReplace:
+		return strings.ReplaceAll(message.Message, "|", r.invalidCharacterReplaceChar)
+	case InvalidCharacterCallback:
+		newMessage, ok := r.invalidCharacterCallback(message)
+		if !ok {
+			r.debug("text dropped", "text", message.Message)
+			return ""
+		}
+		return strings.ReplaceAll(newMessage.Message, "|", "")
+	default:
+		return strings.ReplaceAll(message.Message, "|", "")
+	}
+}
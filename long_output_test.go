@@ -0,0 +1,87 @@
+package monitoringplugin
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestResponse_AttachLongOutput(t *testing.T) {
+	r := NewResponse("checked")
+	assert.Error(t, r.AttachLongOutput(strings.NewReader("log"), 0))
+
+	assert.NoError(t, r.AttachLongOutput(strings.NewReader("line1\nline2\nline|3\n"), 14))
+	res := r.GetInfo()
+	assert.Equal(t, "OK: checked\nline2\nline3", res.RawOutput)
+
+	r = NewResponse("checked")
+	assert.NoError(t, r.AttachLongOutput(strings.NewReader("line1\nline2\n"), 1024))
+	r.UpdateStatus(WARNING, "warning")
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("metric", 1)))
+	res = r.GetInfo()
+	assert.Equal(t, "WARNING: warning\nline1\nline2 | 'metric'=1", res.RawOutput)
+}
+
+func TestResponse_AttachLongOutput_Callback(t *testing.T) {
+	r := NewResponse("checked")
+	assert.NoError(t, r.SetInvalidCharacterCallback(func(message OutputMessage) (OutputMessage, bool) {
+		message.Message = strings.ReplaceAll(message.Message, "|", "/")
+		return message, !strings.Contains(message.Message, "secret")
+	}))
+	assert.NoError(t, r.AttachLongOutput(strings.NewReader("a|b\n"), 1024))
+	assert.NoError(t, r.AttachLongOutput(strings.NewReader("secret|token\n"), 1024))
+	assert.NoError(t, r.AttachLongOutput(strings.NewReader("c\n"), 1024))
+	assert.Equal(t, "OK: checked\na/b\nc", r.GetInfo().RawOutput)
+}
+
+func TestResponse_SetSummary(t *testing.T) {
+	r := NewResponse("checked")
+	r.SetSummary("2 of 3 disks are fine")
+	assert.Equal(t, "OK: 2 of 3 disks are fine", r.GetInfo().RawOutput)
+
+	r.UpdateStatus(WARNING, "disk1 is almost full")
+	r.UpdateStatus(OK, "disk2 is fine")
+	r.AddLongOutput("details|more details")
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("disk1", 95).SetUnit("%")))
+	res := r.GetInfo()
+	assert.Equal(t, "WARNING: 2 of 3 disks are fine | 'disk1'=95%\ndisk1 is almost full\ndisk2 is fine\ndetailsmore details", res.RawOutput)
+
+	r.SetQuiet(true)
+	assert.Equal(t, "WARNING: 2 of 3 disks are fine | 'disk1'=95%", r.GetInfo().RawOutput)
+}
+
+func TestResponse_EnableStatusSummary(t *testing.T) {
+	r := NewResponse("checked")
+	r.EnableStatusSummary()
+	assert.Equal(t, "OK: checked", r.GetInfo().RawOutput)
+
+	r.UpdateStatus(OK, "item1 is fine")
+	r.UpdateStatus(CRITICAL, "item2 is down")
+	r.UpdateStatus(WARNING, "item3 is slow")
+	r.UpdateStatus(OK, "item4 is fine")
+	assert.Equal(t, "CRITICAL: 1 critical, 1 warning, 2 ok\nitem2 is down\nitem3 is slow\nitem1 is fine\nitem4 is fine", r.GetInfo().RawOutput)
+
+	r.SetSummary("4 items checked")
+	r.SetQuiet(true)
+	assert.Equal(t, "CRITICAL: 1 critical, 1 warning, 2 ok - 4 items checked", r.GetInfo().RawOutput)
+}
+
+func TestResponse_SetSummary_Callback(t *testing.T) {
+	r := NewResponse("checked")
+	assert.NoError(t, r.SetInvalidCharacterCallback(func(message OutputMessage) (OutputMessage, bool) {
+		message.Message = strings.ReplaceAll(message.Message, "|", " or ")
+		return message, message.Status != OK
+	}))
+	r.SetSummary("disk1|disk2 almost full")
+	r.AddLongOutput("details|more details")
+	assert.Equal(t, "OK: checked", r.GetInfo().RawOutput)
+
+	r.UpdateStatus(WARNING, "disk1 almost full")
+	r.SetSummary("disk1|disk2 almost full")
+	r.AddLongOutput("details|more details")
+	sub := NewSubCheck()
+	sub.UpdateStatus(WARNING, "disk1|disk2")
+	r.AddSubCheck("disks", sub)
+	assert.Equal(t, "WARNING: disk1 or disk2 almost full\ndisk1 almost full\n\\_ [WARNING] disks\n    disk1 or disk2\n"+
+		"details or more details", r.GetInfo().RawOutput)
+}
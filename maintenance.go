@@ -0,0 +1,64 @@
+package monitoringplugin
+
+import (
+	"github.com/pkg/errors"
+	"os"
+	"strings"
+)
+
+// MaintenanceModeEnv is the environment variable that is used by ApplyMaintenanceMode() to enable the maintenance mode.
+// Its value is the maximum status of the check plugin during maintenance, either "OK" or "WARNING" (or "0" / "1").
+const MaintenanceModeEnv = "MONITORINGPLUGIN_MAINTENANCE"
+
+const maintenancePrefix = "[maintenance] "
+
+/*
+SetMaintenanceMode enables the maintenance mode. During maintenance the status of the check plugin is capped at the
+given maximum status (OK or WARNING) and all output messages are prefixed with "[maintenance]", so checks keep
+collecting performance data during planned work without alerting anyone. Like SetMaxStatus(Status), the cap is
+applied at output time, the computed status is still available in the ResponseInfo.
+*/
+func (r *Response) SetMaintenanceMode(maxStatus Status) error {
+	if maxStatus != OK && maxStatus != WARNING {
+		return errors.New("maximum status during maintenance must be OK or WARNING")
+	}
+	r.maintenanceMode = true
+	r.maintenanceMaxStatus = maxStatus
+	return nil
+}
+
+/*
+ApplyMaintenanceMode enables the maintenance mode (see SetMaintenanceMode(Status)) if the environment variable
+MONITORINGPLUGIN_MAINTENANCE is set. It returns true if the maintenance mode was enabled.
+Usage:
+	_, err := Response.ApplyMaintenanceMode()
+	if err != nil {
+		...
+	}
+*/
+func (r *Response) ApplyMaintenanceMode() (bool, error) {
+	value, ok := os.LookupEnv(MaintenanceModeEnv)
+	if !ok || value == "" {
+		return false, nil
+	}
+	maxStatus, err := ParseStatusStrict(value)
+	if err != nil {
+		return false, errors.Wrap(err, "invalid value of "+MaintenanceModeEnv)
+	}
+	if err := r.SetMaintenanceMode(maxStatus); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// applyMaintenanceMode prefixes the output messages during maintenance, the status is capped by outputStatus().
+func (r *Response) applyMaintenanceMode() {
+	if !r.maintenanceMode {
+		return
+	}
+	for i, message := range r.outputMessages {
+		if !strings.HasPrefix(message.Message, maintenancePrefix) {
+			r.outputMessages[i].Message = maintenancePrefix + message.Message
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package monitoringplugin
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestResponse_SetMaintenanceMode(t *testing.T) {
+	r := NewResponse("checked")
+	assert.Error(t, r.SetMaintenanceMode(CRITICAL))
+	assert.NoError(t, r.SetMaintenanceMode(WARNING))
+	r.UpdateStatus(CRITICAL, "critical")
+	r.UpdateStatus(OK, "ok")
+	r.validate()
+	res := r.GetInfo()
+	assert.Equal(t, WARNING, res.StatusCode)
+	assert.Equal(t, CRITICAL, res.ComputedStatusCode)
+	assert.Equal(t, CRITICAL, r.GetStatusCode())
+	assert.Equal(t, "WARNING: [maintenance] critical\n[maintenance] ok", res.RawOutput)
+}
+
+func TestResponse_ApplyMaintenanceMode(t *testing.T) {
+	defer os.Unsetenv(MaintenanceModeEnv)
+
+	r := NewResponse("checked")
+	assert.NoError(t, os.Unsetenv(MaintenanceModeEnv))
+	ok, err := r.ApplyMaintenanceMode()
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.NoError(t, os.Setenv(MaintenanceModeEnv, "critical"))
+	_, err = r.ApplyMaintenanceMode()
+	assert.Error(t, err)
+
+	assert.NoError(t, os.Setenv(MaintenanceModeEnv, "0"))
+	ok, err = r.ApplyMaintenanceMode()
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	r.UpdateStatus(WARNING, "warning")
+	assert.Equal(t, "OK: [maintenance] warning", r.GetInfo().RawOutput)
+	assert.Equal(t, WARNING, r.GetStatusCode())
+}
@@ -0,0 +1,78 @@
+package monitoringplugin
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Field is a structured key-value pair that is attached to an OutputMessage.
+type Field struct {
+	Key   string      `yaml:"key" json:"key" xml:"key,attr"`
+	Value interface{} `yaml:"value" json:"value" xml:"value"`
+}
+
+// fieldMissingValue is used as value if a key was passed to UpdateStatusKV without a value.
+const fieldMissingValue = "!MISSING"
+
+/*
+UpdateStatusKV works like UpdateStatus(Status, string), but attaches structured fields to the output message.
+The fields are given as alternating keys and values. The classic text output appends them to the message as
+"key=value", while JSON and YAML keep them as structured fields for downstream processing.
+Usage:
+	Response.UpdateStatusKV(WARNING, "disk almost full", "mount", "/var", "used_percent", 85)
+	//WARNING: disk almost full mount=/var used_percent=85
+*/
+func (r *Response) UpdateStatusKV(statusCode Status, statusMessage string, kv ...interface{}) {
+	defer r.beginModification()()
+	message := OutputMessage{
+		Status:  statusCode,
+		Message: statusMessage,
+		Fields:  fields(kv),
+	}
+	if r.checkFinalizedStatusUpdate(message) {
+		r.updateStatus(message)
+	}
+}
+
+// fields converts alternating keys and values to fields.
+func fields(kv []interface{}) []Field {
+	var res []Field
+	for i := 0; i < len(kv); i += 2 {
+		field := Field{
+			Key:   fmt.Sprint(kv[i]),
+			Value: fieldMissingValue,
+		}
+		if i+1 < len(kv) {
+			field.Value = kv[i+1]
+		}
+		res = append(res, field)
+	}
+	return res
+}
+
+// fieldsText returns the fields in the format "key=value key=value". Values with spaces are quoted and invalid
+// characters are removed.
+func fieldsText(fields []Field) string {
+	var res []string
+	for _, field := range fields {
+		value := fmt.Sprint(field.Value)
+		if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+			value = strconv.Quote(value)
+		}
+		res = append(res, strings.ReplaceAll(field.Key+"="+value, "|", ""))
+	}
+	return strings.Join(res, " ")
+}
+
+// fieldsMap returns the fields as map. If a key is used multiple times, the last value is used.
+func fieldsMap(fields []Field) map[string]interface{} {
+	if len(fields) == 0 {
+		return nil
+	}
+	res := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		res[field.Key] = field.Value
+	}
+	return res
+}
@@ -0,0 +1,57 @@
+package monitoringplugin
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+	"testing"
+)
+
+func TestResponse_UpdateStatusKV(t *testing.T) {
+	r := NewResponse("checked")
+	r.UpdateStatusKV(WARNING, "disk almost full", "mount", "/var", "used_percent", 85)
+	r.UpdateStatusKV(CRITICAL, "disk full", "mount", "/mnt/my disk", "state")
+	r.UpdateStatusKV(OK, "pipe", "value", "a|b")
+	assert.Equal(t, CRITICAL, r.GetStatusCode())
+	assert.Equal(t, `CRITICAL: disk full mount="/mnt/my disk" state=!MISSING
+disk almost full mount=/var used_percent=85
+pipe value=ab`, r.String())
+
+	assert.Equal(t, []Field{{Key: "mount", Value: "/var"}, {Key: "used_percent", Value: 85}}, r.Messages(WARNING)[0].Fields)
+}
+
+func TestResponse_UpdateStatusKV_Deduplication(t *testing.T) {
+	r := NewResponse("checked")
+	r.SetMessageDeduplication(true)
+	r.UpdateStatusKV(WARNING, "disk almost full", "mount", "/var")
+	r.UpdateStatusKV(WARNING, "disk almost full", "mount", "/var")
+	r.UpdateStatusKV(WARNING, "disk almost full", "mount", "/tmp")
+	assert.Equal(t, "WARNING: disk almost full (x2) mount=/var\ndisk almost full mount=/tmp", r.String())
+}
+
+func TestOutputMessage_Fields_Encoding(t *testing.T) {
+	message := OutputMessage{
+		Status:  WARNING,
+		Message: "disk almost full",
+		Fields:  []Field{{Key: "mount", Value: "/var"}, {Key: "used_percent", Value: 85}},
+	}
+
+	b, err := json.Marshal(message)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"status":1,"status_text":"WARNING","message":"disk almost full","fields":{"mount":"/var","used_percent":85}}`, string(b))
+
+	b, err = json.Marshal(OutputMessage{Status: OK, Message: "fine"})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"status":0,"status_text":"OK","message":"fine"}`, string(b))
+
+	b, err = yaml.Marshal(message)
+	assert.NoError(t, err)
+	var decoded map[string]interface{}
+	assert.NoError(t, yaml.Unmarshal(b, &decoded))
+	assert.Equal(t, map[string]interface{}{"mount": "/var", "used_percent": 85}, decoded["fields"])
+
+	b, err = xml.Marshal(message)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), `<fields><field key="mount"><value>/var</value></field>`)
+}
@@ -0,0 +1,42 @@
+package monitoringplugin
+
+import "strings"
+
+// CheckMetadata describes the check plugin that created a response.
+type CheckMetadata struct {
+	Name    string `yaml:"name" json:"name" xml:"name"`
+	Version string `yaml:"version,omitempty" json:"version,omitempty" xml:"version,omitempty"`
+	URL     string `yaml:"url,omitempty" json:"url,omitempty" xml:"url,omitempty"`
+}
+
+// String returns the metadata in the format "name vVersion (url)", e.g. "check_disk v1.4.2".
+func (m CheckMetadata) String() string {
+	text := m.Name
+	if m.Version != "" {
+		text += " v" + strings.TrimPrefix(m.Version, "v")
+	}
+	if m.URL != "" {
+		text += " (" + m.URL + ")"
+	}
+	return strings.TrimSpace(text)
+}
+
+/*
+SetCheckMetadata sets the name, version and documentation URL of the check plugin. The metadata is contained in the
+ResponseInfo, which gives operators provenance for results collected across many check plugins.
+Version and URL are optional.
+Usage:
+	Response.SetCheckMetadata("check_disk", "1.4.2", "https://example.com/docs/check_disk")
+*/
+func (r *Response) SetCheckMetadata(name, version, url string) {
+	r.checkMetadata = &CheckMetadata{
+		Name:    name,
+		Version: version,
+		URL:     url,
+	}
+}
+
+// PrintCheckMetadata activates or deactivates appending the check metadata to the output, e.g. "check_disk v1.4.2".
+func (r *Response) PrintCheckMetadata(b bool) {
+	r.printCheckMetadata = b
+}
@@ -0,0 +1,32 @@
+package monitoringplugin
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCheckMetadata_String(t *testing.T) {
+	assert.Equal(t, "check_disk", CheckMetadata{Name: "check_disk"}.String())
+	assert.Equal(t, "check_disk v1.4.2", CheckMetadata{Name: "check_disk", Version: "v1.4.2"}.String())
+	assert.Equal(t, "check_disk v1.4.2 (https://example.com)", CheckMetadata{Name: "check_disk", Version: "1.4.2", URL: "https://example.com"}.String())
+}
+
+func TestResponse_SetCheckMetadata(t *testing.T) {
+	r := NewResponse("checked")
+	assert.Nil(t, r.GetInfo().Check)
+
+	r.SetCheckMetadata("check_disk", "1.4.2", "")
+	r.UpdateStatus(WARNING, "disk almost full")
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("usage", 85).SetUnit("%")))
+	info := r.GetInfo()
+	assert.Equal(t, &CheckMetadata{Name: "check_disk", Version: "1.4.2"}, info.Check)
+	assert.Equal(t, "WARNING: disk almost full | 'usage'=85%", info.RawOutput)
+
+	b, err := json.Marshal(info)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), `"check":{"name":"check_disk","version":"1.4.2"}`)
+
+	r.PrintCheckMetadata(true)
+	assert.Equal(t, "WARNING: disk almost full\ncheck_disk v1.4.2 | 'usage'=85%", r.String())
+}
@@ -0,0 +1,76 @@
+package monitoringplugin
+
+import "sort"
+
+// MetricOption configures the PerformanceDataPoints that are created by Response.AddMetrics.
+type MetricOption func(point *PerformanceDataPoint)
+
+// WithMetricUnit sets the unit of the created performance data points (see PerformanceDataPoint.SetUnit(string)).
+func WithMetricUnit(unit string) MetricOption {
+	return func(point *PerformanceDataPoint) {
+		point.SetUnit(unit)
+	}
+}
+
+// WithMetricThresholds sets the thresholds of the created performance data points
+// (see PerformanceDataPoint.SetThresholds(Thresholds)).
+func WithMetricThresholds(thresholds Thresholds) MetricOption {
+	return func(point *PerformanceDataPoint) {
+		point.SetThresholds(thresholds)
+	}
+}
+
+// WithMetricMin sets the minimum value of the created performance data points
+// (see PerformanceDataPoint.SetMin(interface{})).
+func WithMetricMin(min interface{}) MetricOption {
+	return func(point *PerformanceDataPoint) {
+		point.SetMin(min)
+	}
+}
+
+// WithMetricMax sets the maximum value of the created performance data points
+// (see PerformanceDataPoint.SetMax(interface{})).
+func WithMetricMax(max interface{}) MetricOption {
+	return func(point *PerformanceDataPoint) {
+		point.SetMax(max)
+	}
+}
+
+// WithMetricLabel sets the label of the created performance data points (see PerformanceDataPoint.SetLabel(string)).
+func WithMetricLabel(label string) MetricOption {
+	return func(point *PerformanceDataPoint) {
+		point.SetLabel(label)
+	}
+}
+
+/*
+AddMetrics creates one PerformanceDataPoint per entry of the map, with the key as metric and the value as value, and
+adds them like AddPerformanceDataPoints(...*PerformanceDataPoint). The options are applied to all created points in the
+given order. The points are added in alphabetical order of their metrics.
+Usage:
+	err := Response.AddMetrics(map[string]float64{
+		"load1":  0.5,
+		"load5":  0.7,
+		"load15": 0.9,
+	}, WithMetricMin(0), WithMetricThresholds(NewThresholds(nil, 4, nil, 8)))
+	if err != nil {
+		...
+	}
+*/
+func (r *Response) AddMetrics(metrics map[string]float64, opts ...MetricOption) error {
+	names := make([]string, 0, len(metrics))
+	for name := range metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	points := make([]*PerformanceDataPoint, 0, len(metrics))
+	for _, name := range names {
+		point := NewPerformanceDataPoint(name, metrics[name])
+		for _, opt := range opts {
+			opt(point)
+		}
+		points = append(points, point)
+	}
+	return r.AddPerformanceDataPoints(points...)
+}
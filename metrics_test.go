@@ -0,0 +1,27 @@
+package monitoringplugin
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestResponse_AddMetrics(t *testing.T) {
+	r := NewResponse("checked")
+	err := r.AddMetrics(map[string]float64{
+		"load5":  0.7,
+		"load1":  5,
+		"load15": 0.9,
+	}, WithMetricMin(0), WithMetricThresholds(NewThresholds(nil, 4, nil, 8)), WithMetricLabel("cpu"))
+	assert.NoError(t, err)
+	assert.Equal(t, "'load1_cpu'=5;~:4;~:8;0; 'load15_cpu'=0.9;~:4;~:8;0; 'load5_cpu'=0.7;~:4;~:8;0;",
+		r.performanceDataOutput())
+	assert.Equal(t, WARNING, r.GetStatusCode())
+
+	err = r.AddMetrics(map[string]float64{
+		"memory": 50,
+		"swap":   150,
+	}, WithMetricUnit("%"), WithMetricMax(100))
+	assert.Error(t, err)
+	assert.NotNil(t, r.GetPerformanceDataPoint("memory", ""))
+	assert.Nil(t, r.GetPerformanceDataPoint("swap", ""))
+}
@@ -0,0 +1,97 @@
+package monitoringplugin
+
+import (
+	"io"
+)
+
+// Option configures a Response when it is created with NewResponse.
+type Option func(*Response)
+
+// WithDelimiter sets the output delimiter (see Response.SetOutputDelimiter(string)).
+func WithDelimiter(delimiter string) Option {
+	return func(r *Response) {
+		r.SetOutputDelimiter(delimiter)
+	}
+}
+
+// WithInvalidCharacterBehavior sets the invalid character behavior (see Response.SetInvalidCharacterBehavior).
+// If the behavior is invalid, the default behavior is kept.
+func WithInvalidCharacterBehavior(behavior InvalidCharacterBehavior, replaceCharacter string) Option {
+	return func(r *Response) {
+		_ = r.SetInvalidCharacterBehavior(behavior, replaceCharacter)
+	}
+}
+
+// WithoutPerformanceData deactivates printing performance data (see Response.PrintPerformanceData(bool)).
+func WithoutPerformanceData() Option {
+	return func(r *Response) {
+		r.PrintPerformanceData(false)
+	}
+}
+
+// WithSortedMessages activates or deactivates sorting the output messages by status
+// (see Response.SortOutputMessagesByStatus(bool)).
+func WithSortedMessages(b bool) Option {
+	return func(r *Response) {
+		r.SortOutputMessagesByStatus(b)
+	}
+}
+
+// WithPerformanceDataJSONLabel activates or deactivates JSON labels for performance data
+// (see Response.SetPerformanceDataJSONLabel(bool)).
+func WithPerformanceDataJSONLabel(jsonLabel bool) Option {
+	return func(r *Response) {
+		r.SetPerformanceDataJSONLabel(jsonLabel)
+	}
+}
+
+// WithQuiet activates the quiet mode, which only prints the status line (see Response.SetQuiet(bool)).
+func WithQuiet() Option {
+	return func(r *Response) {
+		r.SetQuiet(true)
+	}
+}
+
+// WithOutputWriter sets the writer the output is printed to (see Response.SetOutputWriter(io.Writer)).
+func WithOutputWriter(w io.Writer) Option {
+	return func(r *Response) {
+		r.SetOutputWriter(w)
+	}
+}
+
+// WithIgnoreSIGPIPE activates ignoring SIGPIPE in OutputAndExit() (see Response.SetIgnoreSIGPIPE(bool)).
+func WithIgnoreSIGPIPE() Option {
+	return func(r *Response) {
+		r.SetIgnoreSIGPIPE(true)
+	}
+}
+
+// WithExitFunc sets the function that is called with the exit code (see Response.SetExitFunc(func(int))).
+func WithExitFunc(exitFunc func(int)) Option {
+	return func(r *Response) {
+		r.SetExitFunc(exitFunc)
+	}
+}
+
+// WithDefaultOkMessagef sets the default OK message according to a format specifier
+// (see Response.SetDefaultOkMessagef(string, ...interface{})).
+func WithDefaultOkMessagef(format string, a ...interface{}) Option {
+	return func(r *Response) {
+		r.SetDefaultOkMessagef(format, a...)
+	}
+}
+
+// WithDefaultOkMessageFunc sets a function that returns the default OK message when the output is generated
+// (see Response.SetDefaultOkMessageFunc(func() string)).
+func WithDefaultOkMessageFunc(fn func() string) Option {
+	return func(r *Response) {
+		r.SetDefaultOkMessageFunc(fn)
+	}
+}
+
+// WithMetricPrefix sets a prefix for the metrics of all performance data points (see Response.SetMetricPrefix(string)).
+func WithMetricPrefix(prefix string) Option {
+	return func(r *Response) {
+		r.SetMetricPrefix(prefix)
+	}
+}
@@ -0,0 +1,51 @@
+package monitoringplugin
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"testing"
+)
+
+func TestNewResponseOptions(t *testing.T) {
+	var buffer bytes.Buffer
+	exitCode := -1
+	r := NewResponse("checked",
+		WithDelimiter(" / "),
+		WithInvalidCharacterBehavior(InvalidCharacterReplace, "-"),
+		WithoutPerformanceData(),
+		WithSortedMessages(false),
+		WithPerformanceDataJSONLabel(true),
+		WithOutputWriter(&buffer),
+		WithExitFunc(func(code int) {
+			exitCode = code
+		}),
+	)
+	assert.Equal(t, LabelEncodingJSON, r.labelEncoding)
+	r.UpdateStatus(OK, "message|1")
+	r.UpdateStatus(WARNING, "message2")
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("metric", 1)))
+	r.OutputAndExit()
+	assert.Equal(t, 1, exitCode)
+	assert.Equal(t, "WARNING: message-1 / message2\n", buffer.String())
+
+	assert.False(t, r.ignoreSIGPIPE)
+
+	r = NewResponse("checked", WithInvalidCharacterBehavior(InvalidCharacterReplace, ""))
+	assert.Equal(t, InvalidCharacterRemove, r.invalidCharacterBehaviour)
+
+	r = NewResponse("checked", WithIgnoreSIGPIPE())
+	assert.True(t, r.ignoreSIGPIPE)
+}
+
+func TestWithDefaultOkMessage(t *testing.T) {
+	r := NewResponse("checked", WithDefaultOkMessagef("%d disks checked", 12))
+	assert.Equal(t, "OK: 12 disks checked", r.String())
+
+	var checked int
+	r = NewResponse("checked", WithDefaultOkMessageFunc(func() string {
+		return strconv.Itoa(checked) + " disks checked"
+	}))
+	checked = 3
+	assert.Equal(t, "OK: 3 disks checked", r.String())
+}
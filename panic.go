@@ -0,0 +1,37 @@
+package monitoringplugin
+
+import (
+	"fmt"
+	"runtime/debug"
+	"strings"
+)
+
+/*
+RecoverPanic recovers from a panic in the check plugin logic, adds an UNKNOWN message containing the panic message to
+the response and exits via OutputAndExit(), instead of crashing with a go traceback. If there is no panic, nothing
+happens. It has to be deferred directly. If the output was already written by OutputAndExit(), the panic is only
+recovered, so the output is never written twice.
+Example:
+	Response := NewResponse("everything checked!")
+	defer Response.OutputAndExit()
+	defer Response.RecoverPanic()
+
+	//check plugin logic...
+*/
+func (r *Response) RecoverPanic() {
+	p := recover()
+	if p == nil || r.exited {
+		return
+	}
+	r.UpdateStatus(UNKNOWN, fmt.Sprint("plugin panicked: ", p))
+	if r.printStackTraceOnPanic {
+		r.AddLongOutput(strings.TrimRight(string(debug.Stack()), "\n"))
+	}
+	r.OutputAndExit()
+}
+
+// PrintStackTraceOnPanic activates or deactivates adding the stack trace to the long output if RecoverPanic()
+// recovers from a panic.
+func (r *Response) PrintStackTraceOnPanic(b bool) {
+	r.printStackTraceOnPanic = b
+}
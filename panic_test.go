@@ -0,0 +1,76 @@
+package monitoringplugin
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestResponse_RecoverPanic(t *testing.T) {
+	var buffer bytes.Buffer
+	exitCode := -1
+	r := NewResponse("checked")
+	r.SetOutputWriter(&buffer)
+	r.SetExitFunc(func(code int) {
+		exitCode = code
+	})
+	func() {
+		defer r.RecoverPanic()
+		r.UpdateStatus(WARNING, "warning")
+		panic("something went wrong")
+	}()
+	assert.Equal(t, 3, exitCode)
+	assert.Equal(t, "UNKNOWN: plugin panicked: something went wrong\nwarning\n", buffer.String())
+
+	buffer.Reset()
+	r = NewResponse("checked")
+	r.SetOutputWriter(&buffer)
+	r.SetExitFunc(func(code int) {})
+	r.PrintStackTraceOnPanic(true)
+	func() {
+		defer r.RecoverPanic()
+		panic("something went wrong")
+	}()
+	assert.True(t, strings.HasPrefix(buffer.String(), "UNKNOWN: plugin panicked: something went wrong\ngoroutine "))
+
+	buffer.Reset()
+	r = NewResponse("checked")
+	r.SetOutputWriter(&buffer)
+	func() {
+		defer r.RecoverPanic()
+	}()
+	assert.Equal(t, "", buffer.String())
+}
+
+func TestResponse_RecoverPanic_OutputOnce(t *testing.T) {
+	var buffer bytes.Buffer
+	exitCodes := 0
+	r := NewResponse("checked")
+	r.SetOutputWriter(&buffer)
+	r.SetExitFunc(func(code int) {
+		exitCodes++
+	})
+	func() {
+		defer r.OutputAndExit()
+		defer r.RecoverPanic()
+		panic("something went wrong")
+	}()
+	assert.Equal(t, 1, exitCodes)
+	assert.Equal(t, "UNKNOWN: plugin panicked: something went wrong\n", buffer.String())
+
+	buffer.Reset()
+	exitCodes = 0
+	r = NewResponse("checked")
+	r.SetOutputWriter(&buffer)
+	r.SetExitFunc(func(code int) {
+		exitCodes++
+	})
+	func() {
+		defer r.RecoverPanic()
+		defer r.OutputAndExit()
+		panic("something went wrong")
+	}()
+	assert.Equal(t, 1, exitCodes)
+	assert.Equal(t, "OK: checked\n", buffer.String())
+}
@@ -0,0 +1,116 @@
+// Package perfdata provides tools for working with the performance data of check plugin results, e.g. to compare
+// consecutive check runs.
+package perfdata
+
+import (
+	"fmt"
+	monitoringplugin "github.com/inexio/go-monitoringplugin/v3"
+	"math"
+	"math/big"
+)
+
+// Change describes a performance data point that is contained in both results, but differs in its value, unit,
+// thresholds, min or max.
+type Change struct {
+	Previous monitoringplugin.PerformanceDataPoint
+	Current  monitoringplugin.PerformanceDataPoint
+	// Delta is the current value minus the previous value. It is NaN if one of the values is unknown.
+	Delta float64
+	// DeltaPercent is the delta in percent of the previous value. It is 0 if the previous value is 0.
+	DeltaPercent float64
+}
+
+// Result contains the differences between the performance data of two results.
+type Result struct {
+	Added   []monitoringplugin.PerformanceDataPoint
+	Removed []monitoringplugin.PerformanceDataPoint
+	Changed []Change
+}
+
+// IsEmpty returns true if the performance data of both results is equal.
+func (r Result) IsEmpty() bool {
+	return len(r.Added) == 0 && len(r.Removed) == 0 && len(r.Changed) == 0
+}
+
+/*
+Diff compares the performance data of two results, e.g. of consecutive check runs or of a check plugin before and
+after a refactoring. Performance data points are matched by metric and label. Added and changed points are returned in
+the order of the current result, removed points in the order of the previous result.
+Usage:
+
+	diff := perfdata.Diff(previousInfo, Response.GetInfo())
+	for _, change := range diff.Changed {
+		fmt.Printf("%s changed by %g\n", change.Current.Metric, change.Delta)
+	}
+*/
+func Diff(prev, curr monitoringplugin.ResponseInfo) Result {
+	type key struct {
+		metric, label string
+	}
+	previous := make(map[key]monitoringplugin.PerformanceDataPoint, len(prev.PerformanceData))
+	for _, point := range prev.PerformanceData {
+		previous[key{point.Metric, point.Label}] = point
+	}
+	current := make(map[key]struct{}, len(curr.PerformanceData))
+
+	var res Result
+	for _, point := range curr.PerformanceData {
+		k := key{point.Metric, point.Label}
+		current[k] = struct{}{}
+		prevPoint, ok := previous[k]
+		switch {
+		case !ok:
+			res.Added = append(res.Added, point)
+		case !equal(prevPoint, point):
+			res.Changed = append(res.Changed, change(prevPoint, point))
+		}
+	}
+	for _, point := range prev.PerformanceData {
+		if _, ok := current[key{point.Metric, point.Label}]; !ok {
+			res.Removed = append(res.Removed, point)
+		}
+	}
+	return res
+}
+
+// equal returns true if both performance data points have the same output.
+func equal(a, b monitoringplugin.PerformanceDataPoint) bool {
+	return string(monitoringplugin.DataPointInfo{PerformanceDataPoint: a}.DefaultOutput(false)) ==
+		string(monitoringplugin.DataPointInfo{PerformanceDataPoint: b}.DefaultOutput(false))
+}
+
+// change returns the Change between both performance data points.
+func change(prev, curr monitoringplugin.PerformanceDataPoint) Change {
+	c := Change{
+		Previous: prev,
+		Current:  curr,
+		Delta:    math.NaN(),
+	}
+	prevValue, ok := value(prev)
+	if !ok {
+		return c
+	}
+	currValue, ok := value(curr)
+	if !ok {
+		return c
+	}
+	c.Delta = currValue - prevValue
+	if prevValue != 0 {
+		c.DeltaPercent = c.Delta / math.Abs(prevValue) * 100
+	}
+	return c
+}
+
+// value returns the value of the performance data point as float64. It returns false if the value is unknown or can
+// not be parsed.
+func value(point monitoringplugin.PerformanceDataPoint) (float64, bool) {
+	if point.IsValueUnknown() {
+		return 0, false
+	}
+	var f big.Float
+	if _, _, err := f.Parse(fmt.Sprint(point.Value), 10); err != nil {
+		return 0, false
+	}
+	res, _ := f.Float64()
+	return res, true
+}
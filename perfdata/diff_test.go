@@ -0,0 +1,45 @@
+package perfdata
+
+import (
+	monitoringplugin "github.com/inexio/go-monitoringplugin/v3"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	prev := monitoringplugin.NewResponse("checked")
+	assert.NoError(t, prev.AddPerformanceDataPoint(monitoringplugin.NewPerformanceDataPoint("load", 2)))
+	assert.NoError(t, prev.AddPerformanceDataPoint(monitoringplugin.NewPerformanceDataPoint("disk", 50).SetLabel("/")))
+	assert.NoError(t, prev.AddPerformanceDataPoint(monitoringplugin.NewPerformanceDataPoint("users", 3)))
+	assert.NoError(t, prev.AddPerformanceDataPoint(monitoringplugin.NewPerformanceDataPoint("uptime", 100)))
+
+	curr := monitoringplugin.NewResponse("checked")
+	assert.NoError(t, curr.AddPerformanceDataPoint(monitoringplugin.NewPerformanceDataPoint("load", 3)))
+	assert.NoError(t, curr.AddPerformanceDataPoint(monitoringplugin.NewPerformanceDataPoint("disk", 50).SetLabel("/")))
+	assert.NoError(t, curr.AddPerformanceDataPoint(monitoringplugin.NewPerformanceDataPoint("users", 3).SetMax(10)))
+	assert.NoError(t, curr.AddPerformanceDataPoint(monitoringplugin.NewUnknownPerformanceDataPoint("uptime")))
+	assert.NoError(t, curr.AddPerformanceDataPoint(monitoringplugin.NewPerformanceDataPoint("disk", 20).SetLabel("/var")))
+
+	diff := Diff(prev.GetInfo(), curr.GetInfo())
+	assert.False(t, diff.IsEmpty())
+	if assert.Len(t, diff.Added, 1) {
+		assert.Equal(t, "/var", diff.Added[0].Label)
+	}
+	assert.Empty(t, diff.Removed)
+	if assert.Len(t, diff.Changed, 3) {
+		assert.Equal(t, "load", diff.Changed[0].Current.Metric)
+		assert.Equal(t, 1.0, diff.Changed[0].Delta)
+		assert.Equal(t, 50.0, diff.Changed[0].DeltaPercent)
+		assert.Equal(t, "users", diff.Changed[1].Current.Metric)
+		assert.Equal(t, 0.0, diff.Changed[1].Delta)
+		assert.True(t, math.IsNaN(diff.Changed[2].Delta))
+	}
+
+	diff = Diff(curr.GetInfo(), prev.GetInfo())
+	if assert.Len(t, diff.Removed, 1) {
+		assert.Equal(t, "/var", diff.Removed[0].Label)
+	}
+
+	assert.True(t, Diff(prev.GetInfo(), prev.GetInfo()).IsEmpty())
+}
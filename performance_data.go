@@ -2,12 +2,14 @@ package monitoringplugin
 
 import (
 	"bytes"
-	"encoding/json"
 	"fmt"
 	"github.com/pkg/errors"
 	"math/big"
 	"regexp"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type performanceDataPointKey struct {
@@ -35,36 +37,95 @@ func (p *performanceData) add(point *PerformanceDataPoint) error {
 	}
 	key := performanceDataPointKey{point.Metric, point.Label}
 	if _, ok := (*p)[key]; ok {
-		return fmt.Errorf("a performance data point with the metric '%s' does already exist", func(key performanceDataPointKey) string {
-			res := key.Metric
-			if key.Label != "" {
-				res += " and label " + key.Label
-			}
-			return res
-		}(key))
+		return point.validationError("metric", "a performance data point with the same metric and label does already exist",
+			nil)
 	}
 	(*p)[key] = *point
 	return nil
 }
 
-// getInfo returns all information for performance data.
+// getInfo returns all information for performance data in insertion order.
 func (p performanceData) getInfo() []PerformanceDataPoint {
 	var info []PerformanceDataPoint
-	for _, pd := range p {
-		info = append(info, pd)
+	for _, key := range p.sortedKeys() {
+		info = append(info, p[key])
 	}
+	sort.SliceStable(info, func(i, j int) bool {
+		return info[i].sequence < info[j].sequence
+	})
 	return info
 }
 
+/*
+summary returns a short summary of the collected performance data, e.g.
+"12 metrics collected, all within thresholds (worst: memory_usage 78%)".
+The worst PerformanceDataPoint is the one that is closest to one of its thresholds.
+*/
+func (p performanceData) summary() string {
+	if len(p) == 0 {
+		return ""
+	}
+
+	res := strconv.Itoa(len(p)) + " metrics collected"
+	if len(p) == 1 {
+		res = "1 metric collected"
+	}
+
+	var worst *PerformanceDataPoint
+	var worstRatio float64
+	for _, key := range p.sortedKeys() {
+		point := p[key]
+		ratio, ok := point.thresholdRatio()
+		if ok && (worst == nil || ratio > worstRatio) {
+			worst, worstRatio = &point, ratio
+		}
+	}
+	if worst != nil {
+		res += ", all within thresholds (worst: " + worst.name() + " " + worst.formatNumber(worst.Value) + worst.Unit + ")"
+	}
+	return res
+}
+
+// sortedKeys returns the keys of all performanceDataPoints sorted by metric and label.
+func (p performanceData) sortedKeys() []performanceDataPointKey {
+	keys := make([]performanceDataPointKey, 0, len(p))
+	for key := range p {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Metric != keys[j].Metric {
+			return keys[i].Metric < keys[j].Metric
+		}
+		return keys[i].Label < keys[j].Label
+	})
+	return keys
+}
+
+// unknownValue is the value of a PerformanceDataPoint whose value could not be determined.
+const unknownValue = "U"
+
 // PerformanceDataPoint contains all information of one PerformanceDataPoint.
 type PerformanceDataPoint struct {
-	Metric     string      `json:"metric" xml:"metric"`
-	Label      string      `json:"label" xml:"label"`
-	Value      interface{} `json:"value" xml:"value"`
-	Unit       string      `json:"unit" xml:"unit"`
-	Thresholds Thresholds  `json:"thresholds" xml:"thresholds"`
-	Min        interface{} `json:"min" xml:"min"`
-	Max        interface{} `json:"max" xml:"max"`
+	Metric      string      `json:"metric" xml:"metric"`
+	Label       string      `json:"label" xml:"label"`
+	Value       interface{} `json:"value" xml:"value"`
+	Unit        string      `json:"unit" xml:"unit"`
+	Thresholds  Thresholds  `json:"thresholds" xml:"thresholds"`
+	Min         interface{} `json:"min" xml:"min"`
+	Max         interface{} `json:"max" xml:"max"`
+	Counter     bool        `json:"counter" xml:"counter"`
+	Timestamp   time.Time   `json:"timestamp" xml:"timestamp"`
+	Description string      `json:"description" xml:"description"`
+
+	precision          *int
+	sequence           int
+	relativeThresholds *relativeThresholds
+	durationUnit       time.Duration
+	derive             func(pd PerfData) float64
+	lazy               func() (float64, error)
+	outputFunc         func(info DataPointInfo, jsonLabel bool) []byte
+	maxPrecision       *int
+	compactQuoting     bool
 }
 
 /*
@@ -74,7 +135,7 @@ This function is used to check if a PerformanceDataPoint is compatible with the
 */
 func (p *PerformanceDataPoint) Validate() error {
 	if p.Metric == "" {
-		return errors.New("data point metric cannot be an empty string")
+		return p.validationError("metric", "data point metric cannot be an empty string", nil)
 	}
 
 	match, err := regexp.MatchString("([='])", p.Metric)
@@ -82,7 +143,7 @@ func (p *PerformanceDataPoint) Validate() error {
 		return errors.Wrap(err, "error during regex match")
 	}
 	if match {
-		return errors.New("metric contains invalid character")
+		return p.validationError("metric", "metric contains invalid character", nil)
 	}
 
 	match, err = regexp.MatchString("([='])", p.Label)
@@ -90,7 +151,7 @@ func (p *PerformanceDataPoint) Validate() error {
 		return errors.Wrap(err, "error during regex match")
 	}
 	if match {
-		return errors.New("metric contains invalid character")
+		return p.validationError("label", "label contains invalid character", nil)
 	}
 
 	match, err = regexp.MatchString("([0-9;'\"])", p.Unit)
@@ -98,41 +159,52 @@ func (p *PerformanceDataPoint) Validate() error {
 		return errors.Wrap(err, "error during regex match")
 	}
 	if match {
-		return errors.New("unit can not contain numbers, semicolon or quotes")
+		return p.validationError("unit", "unit can not contain numbers, semicolon or quotes", nil)
+	}
+
+	if err := p.validateDuration(); err != nil {
+		return err
 	}
 
 	var min, max, value big.Float
-	_, _, err = value.Parse(fmt.Sprint(p.Value), 10)
-	if err != nil {
-		return errors.Wrap(err, "can't parse value")
+	unknown := p.IsValueUnknown()
+	if !unknown {
+		_, _, err = value.SetPrec(numberPrecision).Parse(fmt.Sprint(p.Value), 10)
+		if err != nil {
+			return p.validationError("value", "can't parse value", err)
+		}
 	}
 
 	if p.Min != nil {
-		_, _, err = min.Parse(fmt.Sprint(p.Min), 10)
+		_, _, err = min.SetPrec(numberPrecision).Parse(fmt.Sprint(p.Min), 10)
 		if err != nil {
-			return errors.Wrap(err, "can't parse min")
+			return p.validationError("min", "can't parse min", err)
 		}
 		switch min.Cmp(&value) {
 		case 1:
-			return errors.New("value cannot be smaller than min")
+			if !unknown {
+				return p.validationError("min", "value cannot be smaller than min", nil)
+			}
 		default:
 		}
 	}
 	if p.Max != nil {
-		_, _, err = max.Parse(fmt.Sprint(p.Max), 10)
+		_, _, err = max.SetPrec(numberPrecision).Parse(fmt.Sprint(p.Max), 10)
 		if err != nil {
-			return errors.Wrap(err, "can't parse max")
+			return p.validationError("max", "can't parse max", err)
 		}
 		switch max.Cmp(&value) {
 		case -1:
-			return errors.New("value cannot be larger than max")
+			if !unknown {
+				return p.validationError("max", "value cannot be larger than max", nil)
+			}
 		default:
 		}
 	}
 	if p.Min != nil && p.Max != nil {
 		switch min.Cmp(&max) {
 		case 1:
-			return errors.New("min cannot be larger than max")
+			return p.validationError("max", "min cannot be larger than max", nil)
 		default:
 		}
 	}
@@ -140,13 +212,82 @@ func (p *PerformanceDataPoint) Validate() error {
 	if !p.Thresholds.IsEmpty() {
 		err = p.Thresholds.Validate()
 		if err != nil {
-			return errors.Wrap(err, "thresholds are invalid")
+			return p.validationError("thresholds", "thresholds are invalid", err)
 		}
 	}
 
+	if err := p.validateRelativeThresholds(); err != nil {
+		return err
+	}
+
+	if p.Counter {
+		return p.validateCounter(&value, &min)
+	}
+
+	return nil
+}
+
+// validateCounter validates the properties that are specific to counters (see SetCounter()).
+func (p *PerformanceDataPoint) validateCounter(value, min *big.Float) error {
+	if p.Unit != "c" {
+		return p.validationError("unit", "the unit of a counter must be 'c'", nil)
+	}
+	if !p.IsValueUnknown() && value.Sign() < 0 {
+		return p.validationError("value", "counter value cannot be negative", nil)
+	}
+	if p.Min != nil && min.Sign() != 0 {
+		return p.validationError("min", "min of a counter must be 0", nil)
+	}
+	if !p.Thresholds.IsEmpty() {
+		return p.validationError("thresholds",
+			"thresholds cannot be applied to the raw value of a counter, apply them to its rate instead", nil)
+	}
 	return nil
 }
 
+// name returns a human readable name of the PerformanceDataPoint that is used in output messages.
+func (p *PerformanceDataPoint) name() string {
+	if p.Label != "" {
+		return p.Metric + " (" + p.Label + ")"
+	}
+	return p.Metric
+}
+
+/*
+thresholdRatio returns how close the value is to the thresholds of the PerformanceDataPoint.
+A ratio of 1 means that the value is exactly at a threshold. If no threshold is set or the ratio can not be computed,
+false is returned.
+*/
+func (p *PerformanceDataPoint) thresholdRatio() (float64, bool) {
+	value, err := parseFloat(p.Value)
+	if err != nil {
+		return 0, false
+	}
+
+	var ratio float64
+	var ok bool
+	thresholds := p.Thresholds.outside()
+	upper := thresholds.WarningMax
+	if upper == nil {
+		upper = thresholds.CriticalMax
+	}
+	if upper != nil {
+		if max, err := parseFloat(upper); err == nil && max > 0 {
+			ratio, ok = value/max, true
+		}
+	}
+	lower := thresholds.WarningMin
+	if lower == nil {
+		lower = thresholds.CriticalMin
+	}
+	if lower != nil && value > 0 {
+		if min, err := parseFloat(lower); err == nil && (!ok || min/value > ratio) {
+			ratio, ok = min/value, true
+		}
+	}
+	return ratio, ok
+}
+
 /*
 NewPerformanceDataPoint creates a new PerformanceDataPoint. Metric and value are mandatory but are not checked at this
 point, the performanceDatePoint's validation is checked later when it is added to the performanceData list in the
@@ -163,6 +304,29 @@ func NewPerformanceDataPoint(metric string, value interface{}) *PerformanceDataP
 	}
 }
 
+/*
+NewUnknownPerformanceDataPoint creates a new PerformanceDataPoint whose value could not be determined. The value is
+rendered as 'U' as allowed by the Monitoring Plugins Development Guidelines and is not checked against thresholds.
+Usage:
+	PerformanceDataPoint := NewUnknownPerformanceDataPoint("temperature").SetUnit("C").SetMin(0)
+	//'temperature'=UC;;;0;
+*/
+func NewUnknownPerformanceDataPoint(metric string) *PerformanceDataPoint {
+	return NewPerformanceDataPoint(metric, unknownValue)
+}
+
+// SetValueUnknown marks the value of the performance data point as unknown (see NewUnknownPerformanceDataPoint).
+func (p *PerformanceDataPoint) SetValueUnknown() *PerformanceDataPoint {
+	p.Value = unknownValue
+	return p
+}
+
+// IsValueUnknown returns true if the value of the performance data point could not be determined.
+func (p *PerformanceDataPoint) IsValueUnknown() bool {
+	value, ok := p.Value.(string)
+	return ok && value == unknownValue
+}
+
 // SetUnit sets the unit of the performance data point
 func (p *PerformanceDataPoint) SetUnit(unit string) *PerformanceDataPoint {
 	p.Unit = unit
@@ -178,6 +342,103 @@ func (p *PerformanceDataPoint) SetMin(min interface{}) *PerformanceDataPoint {
 // SetMax sets maximum value.
 func (p *PerformanceDataPoint) SetMax(max interface{}) *PerformanceDataPoint {
 	p.Max = max
+	p.applyRelativeThresholds()
+	return p
+}
+
+/*
+SetCounter marks the performance data point as a continuously increasing counter (e.g. bytes transmitted on an
+interface) and sets the unit to 'c'. Counters must not be negative, their min can only be 0 and thresholds can not be
+applied to them, because only the rate of a counter is meaningful for alerting.
+Usage:
+	PerformanceDataPoint := NewPerformanceDataPoint("ifInOctets", 123456789).SetCounter()
+*/
+func (p *PerformanceDataPoint) SetCounter() *PerformanceDataPoint {
+	p.Counter = true
+	p.Unit = "c"
+	return p
+}
+
+// SetTimestamp sets the time the value was measured, e.g. if results are collected in batch mode.
+// If no timestamp is set, the time the result is written is used by sinks.
+func (p *PerformanceDataPoint) SetTimestamp(timestamp time.Time) *PerformanceDataPoint {
+	p.Timestamp = timestamp
+	return p
+}
+
+/*
+SetFormat sets the number of decimal places that are used to render the value, min and max of the performance data
+point. Values are never rendered in exponent notation, because some performance data parsers reject it.
+Usage:
+	PerformanceDataPoint := NewPerformanceDataPoint("load", 0.123456).SetFormat(2)
+	//'load'=0.12
+*/
+func (p *PerformanceDataPoint) SetFormat(precision int) *PerformanceDataPoint {
+	p.precision = &precision
+	return p
+}
+
+/*
+InferMinMaxFromThresholds sets min and max, if they are not set explicitly, to the outermost thresholds (critical if
+set, otherwise warning), so graphs get sensible axis ranges. If the value is outside the thresholds, the value is used
+instead. The thresholds must be set before.
+Usage:
+	PerformanceDataPoint := NewPerformanceDataPoint("temperature", 32).
+		SetThresholds(NewThresholds(10, 35, 5, 40)).InferMinMaxFromThresholds()
+	//'temperature'=32;10:35;5:40;5;40
+*/
+func (p *PerformanceDataPoint) InferMinMaxFromThresholds() *PerformanceDataPoint {
+	thresholds := p.Thresholds.outside()
+	if p.Min == nil {
+		p.Min = p.inferBound(thresholds.CriticalMin, thresholds.WarningMin, -1)
+	}
+	if p.Max == nil {
+		p.Max = p.inferBound(thresholds.CriticalMax, thresholds.WarningMax, 1)
+	}
+	return p
+}
+
+// inferBound returns the critical threshold or, if not set, the warning threshold. If the value is outside the
+// threshold (sign is 1 for upper and -1 for lower bounds), the value is returned.
+func (p *PerformanceDataPoint) inferBound(critical, warning interface{}, sign int) interface{} {
+	bound := critical
+	if bound == nil {
+		bound = warning
+	}
+	if bound == nil {
+		return nil
+	}
+	b, err := parseFloat(bound)
+	if err != nil {
+		return nil
+	}
+	if v, err := parseFloat(p.Value); err == nil && (v-b)*float64(sign) > 0 {
+		return p.Value
+	}
+	return bound
+}
+
+// SetDescription sets a description of the metric, which is contained in the ResponseInfo and its JSON, YAML and XML
+// encodings, so exported metrics are self-documenting. The description is not part of the performance data output.
+func (p *PerformanceDataPoint) SetDescription(description string) *PerformanceDataPoint {
+	p.Description = description
+	return p
+}
+
+/*
+SetOutputFunc sets a function that renders the performance data point instead of the default format, so special points
+can control their own performance data output. The function receives the point with its status and whether JSON labels
+are activated. DataPointInfo.DefaultOutput(bool) returns the output in the default format, e.g. for post-processing.
+The returned output must not contain spaces outside of the quoted metric.
+Usage:
+	PerformanceDataPoint := NewPerformanceDataPoint("load", 0.5).SetOutputFunc(
+		func(info DataPointInfo, jsonLabel bool) []byte {
+			return []byte(fmt.Sprintf("'%s'=%.3f", info.Metric, info.Value))
+		})
+	//'load'=0.500
+*/
+func (p *PerformanceDataPoint) SetOutputFunc(fn func(info DataPointInfo, jsonLabel bool) []byte) *PerformanceDataPoint {
+	p.outputFunc = fn
 	return p
 }
 
@@ -188,6 +449,29 @@ func (p *PerformanceDataPoint) SetLabel(label string) *PerformanceDataPoint {
 	return p
 }
 
+/*
+WithLabel returns a copy of the performance data point with the given label, which shares the unit, min, max,
+thresholds and all other settings. This makes it easy to emit the same metric for many instances (e.g. per CPU or per
+disk) without repeating the configuration. The value of the copy can be set with SetValue(interface{}).
+Usage:
+	template := NewPerformanceDataPoint("cpu_usage", nil).SetUnit("%").SetMin(0).SetMax(100)
+	for i, usage := range usages {
+		err := Response.AddPerformanceDataPoint(template.WithLabel("cpu" + strconv.Itoa(i)).SetValue(usage))
+		...
+	}
+*/
+func (p *PerformanceDataPoint) WithLabel(label string) *PerformanceDataPoint {
+	point := *p
+	point.Label = label
+	return &point
+}
+
+// SetValue sets the value of the performance data point.
+func (p *PerformanceDataPoint) SetValue(value interface{}) *PerformanceDataPoint {
+	p.Value = value
+	return p
+}
+
 // SetThresholds sets the thresholds for the performance data point
 func (p *PerformanceDataPoint) SetThresholds(thresholds Thresholds) *PerformanceDataPoint {
 	p.Thresholds = thresholds
@@ -196,33 +480,32 @@ func (p *PerformanceDataPoint) SetThresholds(thresholds Thresholds) *Performance
 
 // This function returns the PerformanceDataPoint in the specified format that will be returned by the check plugin.
 func (p *PerformanceDataPoint) output(jsonLabel bool) []byte {
-	var buffer bytes.Buffer
 	if jsonLabel {
-		buffer.WriteByte('\'')
-		key := performanceDataPointKey{
-			Metric: p.Metric,
-			Label:  p.Label,
-		}
-		jsonKey, _ := json.Marshal(key)
-		buffer.Write(jsonKey)
-		buffer.WriteByte('\'')
+		return p.encode(LabelEncodingJSON, defaultLabelKey)
+	}
+	return p.encode(LabelEncodingSuffix, defaultLabelKey)
+}
+
+// encode returns the PerformanceDataPoint in the output format, using the given label encoding and label key.
+func (p *PerformanceDataPoint) encode(encoding LabelEncoding, key string) []byte {
+	if p.outputFunc != nil {
+		return p.outputFunc(DataPointInfo{
+			PerformanceDataPoint: *p,
+			Status:               p.status(),
+		}, encoding == LabelEncodingJSON)
+	}
+	var buffer bytes.Buffer
+	name := p.encodedName(encoding, key)
+	if p.compactQuoting && !requiresQuoting(name) {
+		buffer.WriteString(name)
 	} else {
 		buffer.WriteByte('\'')
-		buffer.WriteString(p.Metric)
-		if p.Label != "" {
-			buffer.WriteByte('_')
-			buffer.WriteString(p.Label)
-		}
+		buffer.WriteString(name)
 		buffer.WriteByte('\'')
 	}
 	buffer.WriteByte('=')
 
-	switch p.Value.(type) {
-	case float64:
-		buffer.WriteString(strconv.FormatFloat(p.Value.(float64), 'f', -1, 64))
-	default:
-		buffer.WriteString(fmt.Sprint(p.Value))
-	}
+	buffer.WriteString(p.formatNumber(p.Value))
 
 	buffer.WriteString(p.Unit)
 
@@ -237,23 +520,68 @@ func (p *PerformanceDataPoint) output(jsonLabel bool) []byte {
 		}
 		buffer.WriteByte(';')
 		if p.Min != nil {
-			switch min := p.Min.(type) {
-			case float64:
-				buffer.WriteString(strconv.FormatFloat(min, 'f', -1, 64))
-			default:
-				buffer.WriteString(fmt.Sprint(min))
-			}
+			buffer.WriteString(p.formatNumber(p.Min))
 		}
 		buffer.WriteByte(';')
 		if p.Max != nil {
-			switch max := p.Max.(type) {
-			case float64:
-				buffer.WriteString(strconv.FormatFloat(max, 'f', -1, 64))
-			default:
-				buffer.WriteString(fmt.Sprint(max))
-			}
+			buffer.WriteString(p.formatNumber(p.Max))
 		}
 	}
 
 	return buffer.Bytes()
 }
+
+// formatNumber returns the string representation of a value, min or max of the PerformanceDataPoint, using the
+// precision set with SetFormat(int) or the maximum precision set with Response.SetMaxPrecision(int).
+func (p *PerformanceDataPoint) formatNumber(v interface{}) string {
+	if p.precision == nil && p.maxPrecision == nil {
+		return formatNumber(v)
+	}
+	var f big.Float
+	if _, _, err := f.SetPrec(numberPrecision).Parse(fmt.Sprint(v), 10); err != nil {
+		return formatNumber(v)
+	}
+	if p.precision != nil {
+		return f.Text('f', *p.precision)
+	}
+	res := formatNumber(v)
+	if i := strings.IndexByte(res, '.'); i >= 0 && len(res)-i-1 > *p.maxPrecision {
+		res = f.Text('f', *p.maxPrecision)
+		if strings.Contains(res, ".") {
+			res = strings.TrimRight(strings.TrimRight(res, "0"), ".")
+		}
+	}
+	return res
+}
+
+// formatNumber returns the string representation of a value, min, max or threshold of a PerformanceDataPoint.
+// Exponent notation is never used.
+func formatNumber(v interface{}) string {
+	switch n := v.(type) {
+	case float64:
+		return strconv.FormatFloat(n, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(n), 'f', -1, 32)
+	case *big.Float:
+		return n.Text('f', -1)
+	default:
+		s := fmt.Sprint(n)
+		if strings.ContainsAny(s, "eE") {
+			var f big.Float
+			if _, _, err := f.Parse(s, 10); err == nil {
+				return f.Text('f', -1)
+			}
+		}
+		return s
+	}
+}
+
+// parseFloat parses a value, min, max or threshold of a PerformanceDataPoint to a float64.
+func parseFloat(v interface{}) (float64, error) {
+	var f big.Float
+	if _, _, err := f.Parse(fmt.Sprint(v), 10); err != nil {
+		return 0, err
+	}
+	res, _ := f.Float64()
+	return res, nil
+}
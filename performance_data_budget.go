@@ -0,0 +1,102 @@
+package monitoringplugin
+
+import (
+	"sort"
+	"strconv"
+)
+
+/*
+SetMaxPerformanceData sets the maximum number of performance data points that are printed. If there are more
+performance data points, the least important ones are omitted first and a line with the number of omitted points is
+appended to the output messages. Points that violate their thresholds are the most important ones, followed by points
+with thresholds and points without thresholds. The ResponseInfo still contains all performance data points.
+A value of 0 or less disables the limit, which is the default.
+Example:
+	Response.SetMaxPerformanceData(20)
+	//... and 12 performance data points omitted
+*/
+func (r *Response) SetMaxPerformanceData(n int) {
+	r.maxPerformanceData = n
+}
+
+/*
+SetMaxPerformanceDataLength sets the maximum length (in bytes) of the printed performance data, which protects
+transports with strict size limits. Performance data points are omitted in the same order as with
+SetMaxPerformanceData(int). A value of 0 or less disables the limit, which is the default.
+*/
+func (r *Response) SetMaxPerformanceDataLength(n int) {
+	r.maxPerformanceDataLength = n
+}
+
+// printedPerformanceData returns the performance data points of the response and its sub-checks that pass the filter
+// (see FilterPerformanceData) in output order and the number of points that are omitted because they exceed the
+// performance data budget.
+func (r *Response) printedPerformanceData() ([]PerformanceDataPoint, int) {
+	points := append(r.performanceData.getInfo(), subCheckPerformanceData(r.subChecks, "")...)
+	points = r.sortPerformanceData(r.filterPerformanceData(points))
+	if r.maxPerformanceData <= 0 && r.maxPerformanceDataLength <= 0 {
+		return points, 0
+	}
+
+	indices := make([]int, len(points))
+	for i := range indices {
+		indices[i] = i
+	}
+	sort.SliceStable(indices, func(i, j int) bool {
+		return points[indices[i]].importance() > points[indices[j]].importance()
+	})
+	keep := make(map[int]bool, len(points))
+	var length int
+	for _, i := range indices {
+		if r.maxPerformanceData > 0 && len(keep) == r.maxPerformanceData {
+			break
+		}
+		pointLength := len(r.pointOutput(&points[i]))
+		if len(keep) > 0 {
+			pointLength++ // separator
+		}
+		if r.maxPerformanceDataLength > 0 && length+pointLength > r.maxPerformanceDataLength {
+			break
+		}
+		length += pointLength
+		keep[i] = true
+	}
+
+	var res []PerformanceDataPoint
+	for i, point := range points {
+		if keep[i] {
+			res = append(res, point)
+		}
+	}
+	if dropped := len(points) - len(res); dropped > 0 {
+		r.debug("performance data points omitted", "dropped", dropped)
+	}
+	return res, len(points) - len(res)
+}
+
+// importance returns how important the PerformanceDataPoint is when the performance data budget is exceeded.
+// Points that violate their thresholds are the most important ones, followed by points with thresholds.
+func (p *PerformanceDataPoint) importance() int {
+	res := statusSeverity(p.status()) * 2
+	if !p.Thresholds.IsEmpty() {
+		res++
+	}
+	return res
+}
+
+// performanceDataOverflow returns the line that indicates omitted performance data points, e.g.
+// "... and 12 performance data points omitted".
+func (r *Response) performanceDataOverflow() string {
+	if !r.printPerformanceData {
+		return ""
+	}
+	_, dropped := r.printedPerformanceData()
+	switch dropped {
+	case 0:
+		return ""
+	case 1:
+		return "... and 1 performance data point omitted"
+	default:
+		return "... and " + strconv.Itoa(dropped) + " performance data points omitted"
+	}
+}
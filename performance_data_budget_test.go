@@ -0,0 +1,27 @@
+package monitoringplugin
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestResponse_SetMaxPerformanceData(t *testing.T) {
+	r := newOrderTestResponse()
+	r.SetMaxPerformanceData(2)
+	assert.Equal(t, "CRITICAL: b is outside of WARNING threshold\nd is outside of CRITICAL threshold\n"+
+		"... and 2 performance data points omitted | 'b'=50;~:10;~:100;; 'd'=200;~:10;~:100;;", r.outputString())
+	assert.Len(t, r.GetInfo().PerformanceData, 4)
+
+	r.SetMaxPerformanceData(0)
+	assert.Equal(t, "'c'=1 'b'=50;~:10;~:100;; 'a'=1;~:10;~:100;; 'd'=200;~:10;~:100;;", r.performanceDataOutput())
+}
+
+func TestResponse_SetMaxPerformanceDataLength(t *testing.T) {
+	r := newOrderTestResponse()
+	r.SetMaxPerformanceDataLength(40)
+	assert.Equal(t, "'b'=50;~:10;~:100;; 'd'=200;~:10;~:100;;", r.performanceDataOutput())
+	assert.Equal(t, "... and 2 performance data points omitted", r.performanceDataOverflow())
+
+	r.PrintPerformanceData(false)
+	assert.Equal(t, "", r.performanceDataOverflow())
+}
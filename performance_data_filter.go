@@ -0,0 +1,125 @@
+package monitoringplugin
+
+import (
+	"github.com/pkg/errors"
+	"regexp"
+	"strings"
+)
+
+// metricPattern matches the names of performance data points.
+type metricPattern func(name string) bool
+
+/*
+FilterPerformanceData sets patterns that filter the printed performance data, so operators can trim noisy metrics,
+e.g. via a command line flag, without changing the code that collects them. If include patterns are given, only
+performance data points that match at least one of them are printed. Points that match an exclude pattern are never
+printed. Patterns are globs (e.g. "if_*_errors") or, if enclosed in slashes, regular expressions (e.g.
+"/^if_(in|out)_octets$/"). In globs, '*' matches any sequence of characters including '/' (e.g. "disk_*" matches
+"disk_/var"), '?' matches a single character and "[...]" matches a character class. They are matched against the
+metric and the metric with the label suffix ("metric_label"). The ResponseInfo still contains all performance data
+points. Calling the function with no patterns removes the filter.
+Usage:
+	err := Response.FilterPerformanceData([]string{"if_*"}, []string{"/_errors$/"})
+	if err != nil {
+		...
+	}
+*/
+func (r *Response) FilterPerformanceData(include, exclude []string) error {
+	includePatterns, err := compileMetricPatterns(include)
+	if err != nil {
+		return errors.Wrap(err, "invalid include pattern")
+	}
+	excludePatterns, err := compileMetricPatterns(exclude)
+	if err != nil {
+		return errors.Wrap(err, "invalid exclude pattern")
+	}
+	r.performanceDataInclude, r.performanceDataExclude = includePatterns, excludePatterns
+	return nil
+}
+
+// compileMetricPatterns compiles glob and regular expression patterns.
+func compileMetricPatterns(patterns []string) ([]metricPattern, error) {
+	var res []metricPattern
+	for _, pattern := range patterns {
+		if len(pattern) > 1 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+			re, err := regexp.Compile(pattern[1 : len(pattern)-1])
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid regular expression '%s'", pattern)
+			}
+			res = append(res, re.MatchString)
+			continue
+		}
+		re, err := compileGlob(pattern)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid glob '%s'", pattern)
+		}
+		res = append(res, re.MatchString)
+	}
+	return res, nil
+}
+
+// compileGlob converts a glob to a regular expression. Unlike path.Match, '*' and '?' also match '/', because metrics
+// and labels often contain paths.
+func compileGlob(glob string) (*regexp.Regexp, error) {
+	var expr strings.Builder
+	expr.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			expr.WriteString(".*")
+		case '?':
+			expr.WriteString(".")
+		case '\\':
+			if i+1 == len(glob) {
+				return nil, errors.New("glob ends with an escape character")
+			}
+			i++
+			expr.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				return nil, errors.New("unterminated character class")
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			expr.WriteString("[" + class + "]")
+			i += end + 1
+		default:
+			end := i + 1
+			for end < len(glob) && !strings.ContainsRune("*?\\[", rune(glob[end])) {
+				end++
+			}
+			expr.WriteString(regexp.QuoteMeta(glob[i:end]))
+			i = end - 1
+		}
+	}
+	expr.WriteString("$")
+	return regexp.Compile(expr.String())
+}
+
+// filterPerformanceData returns the PerformanceDataPoints that pass the filter set with FilterPerformanceData.
+func (r *Response) filterPerformanceData(points []PerformanceDataPoint) []PerformanceDataPoint {
+	if len(r.performanceDataInclude) == 0 && len(r.performanceDataExclude) == 0 {
+		return points
+	}
+	var res []PerformanceDataPoint
+	for _, point := range points {
+		included := len(r.performanceDataInclude) == 0 || point.matchesAny(r.performanceDataInclude)
+		if included && !point.matchesAny(r.performanceDataExclude) {
+			res = append(res, point)
+		}
+	}
+	return res
+}
+
+// matchesAny returns true if the metric or the metric with the label suffix matches one of the patterns.
+func (p *PerformanceDataPoint) matchesAny(patterns []metricPattern) bool {
+	for _, match := range patterns {
+		if match(p.Metric) || p.Label != "" && match(p.Metric+"_"+p.Label) {
+			return true
+		}
+	}
+	return false
+}
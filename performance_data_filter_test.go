@@ -0,0 +1,47 @@
+package monitoringplugin
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestResponse_FilterPerformanceData(t *testing.T) {
+	r := NewResponse("checked")
+	assert.NoError(t, r.AddMetrics(map[string]float64{"if_in_octets": 1, "if_in_errors": 2}, WithMetricLabel("eth0")))
+	assert.NoError(t, r.AddMetrics(map[string]float64{"if_in_octets": 3, "if_in_errors": 4}, WithMetricLabel("eth1")))
+	assert.NoError(t, r.AddMetrics(map[string]float64{"uptime": 5}))
+
+	assert.NoError(t, r.FilterPerformanceData([]string{"if_*"}, []string{"/_errors$/"}))
+	assert.Equal(t, "'if_in_octets_eth0'=1 'if_in_octets_eth1'=3", r.performanceDataOutput())
+
+	assert.NoError(t, r.FilterPerformanceData(nil, []string{"*_eth1"}))
+	assert.Equal(t, "'if_in_errors_eth0'=2 'if_in_octets_eth0'=1 'uptime'=5", r.performanceDataOutput())
+	assert.Len(t, r.GetInfo().PerformanceData, 5)
+
+	assert.NoError(t, r.FilterPerformanceData(nil, nil))
+	points, _ := r.printedPerformanceData()
+	assert.Len(t, points, 5)
+
+	assert.Error(t, r.FilterPerformanceData([]string{"/(/"}, nil))
+	assert.Error(t, r.FilterPerformanceData(nil, []string{"["}))
+}
+
+func TestResponse_FilterPerformanceData_Glob(t *testing.T) {
+	r := NewResponse("checked")
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("disk", 1).SetLabel("/var")))
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("disk", 2).SetLabel("/var/log")))
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("disk", 3).SetLabel("/home")))
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("load1", 4)))
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("load5", 5)))
+
+	assert.NoError(t, r.FilterPerformanceData([]string{"disk_/var*"}, nil))
+	assert.Equal(t, "'disk_/var'=1 'disk_/var/log'=2", r.performanceDataOutput())
+
+	assert.NoError(t, r.FilterPerformanceData([]string{"disk_*"}, []string{"*/log"}))
+	assert.Equal(t, "'disk_/var'=1 'disk_/home'=3", r.performanceDataOutput())
+
+	assert.NoError(t, r.FilterPerformanceData([]string{"load[!5]", "disk_?home"}, nil))
+	assert.Equal(t, "'disk_/home'=3 'load1'=4", r.performanceDataOutput())
+
+	assert.Error(t, r.FilterPerformanceData([]string{"disk\\"}, nil))
+}
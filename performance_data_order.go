@@ -0,0 +1,84 @@
+package monitoringplugin
+
+import (
+	"sort"
+	"strings"
+)
+
+// DataPointInfo contains a PerformanceDataPoint and the status that results from checking its value against its
+// thresholds. It is passed to the comparison function set with Response.SortPerformanceData.
+type DataPointInfo struct {
+	PerformanceDataPoint
+	Status Status
+}
+
+// DefaultOutput returns the performance data point in the default output format, ignoring the function set with
+// PerformanceDataPoint.SetOutputFunc.
+func (i DataPointInfo) DefaultOutput(jsonLabel bool) []byte {
+	point := i.PerformanceDataPoint
+	point.outputFunc = nil
+	return point.output(jsonLabel)
+}
+
+/*
+SortPerformanceData sets the function that is used to order the performance data in the output. The function must
+return a negative number if a is printed before b, a positive number if a is printed after b and 0 if the order does
+not matter, in which case the insertion order is kept. If cmp is nil, the performance data is printed in insertion
+order, which is the default.
+Usage:
+	Response.SortPerformanceData(CompareDataPointsBySeverity)
+*/
+func (r *Response) SortPerformanceData(cmp func(a, b DataPointInfo) int) {
+	r.performanceDataCompare = cmp
+}
+
+// CompareDataPointsAlphabetically orders performance data alphabetically by metric and label.
+func CompareDataPointsAlphabetically(a, b DataPointInfo) int {
+	if res := strings.Compare(a.Metric, b.Metric); res != 0 {
+		return res
+	}
+	return strings.Compare(a.Label, b.Label)
+}
+
+// CompareDataPointsBySeverity orders performance data by the severity of their threshold violations, starting with the
+// worst. Performance data with the same status is ordered alphabetically.
+func CompareDataPointsBySeverity(a, b DataPointInfo) int {
+	if res := statusSeverity(b.Status) - statusSeverity(a.Status); res != 0 {
+		return res
+	}
+	return CompareDataPointsAlphabetically(a, b)
+}
+
+// sortPerformanceData orders the given PerformanceDataPoints using the comparison function set with
+// SortPerformanceData.
+func (r *Response) sortPerformanceData(points []PerformanceDataPoint) []PerformanceDataPoint {
+	if r.performanceDataCompare == nil {
+		return points
+	}
+	infos := make([]DataPointInfo, len(points))
+	for i, point := range points {
+		infos[i] = DataPointInfo{
+			PerformanceDataPoint: point,
+			Status:               point.status(),
+		}
+	}
+	sort.SliceStable(infos, func(i, j int) bool {
+		return r.performanceDataCompare(infos[i], infos[j]) < 0
+	})
+	for i, info := range infos {
+		points[i] = info.PerformanceDataPoint
+	}
+	return points
+}
+
+// status returns the status that results from checking the value against the thresholds of the PerformanceDataPoint.
+func (p *PerformanceDataPoint) status() Status {
+	if p.Thresholds.IsEmpty() || p.IsValueUnknown() {
+		return OK
+	}
+	res, err := p.Thresholds.CheckValue(p.Value)
+	if err != nil {
+		return UNKNOWN
+	}
+	return res
+}
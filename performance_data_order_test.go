@@ -0,0 +1,38 @@
+package monitoringplugin
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func newOrderTestResponse() *Response {
+	r := NewResponse("checked")
+	_ = r.AddPerformanceDataPoint(NewPerformanceDataPoint("c", 1))
+	_ = r.AddPerformanceDataPoint(NewPerformanceDataPoint("b", 50).SetThresholds(NewThresholds(nil, 10, nil, 100)))
+	_ = r.AddPerformanceDataPoint(NewPerformanceDataPoint("a", 1).SetThresholds(NewThresholds(nil, 10, nil, 100)))
+	_ = r.AddPerformanceDataPoint(NewPerformanceDataPoint("d", 200).SetThresholds(NewThresholds(nil, 10, nil, 100)))
+	return r
+}
+
+func metrics(points []PerformanceDataPoint) []string {
+	var res []string
+	for _, point := range points {
+		res = append(res, point.Metric)
+	}
+	return res
+}
+
+func TestResponse_SortPerformanceData(t *testing.T) {
+	r := newOrderTestResponse()
+	assert.Equal(t, []string{"c", "b", "a", "d"}, metrics(r.GetInfo().PerformanceData))
+
+	r.SortPerformanceData(CompareDataPointsAlphabetically)
+	assert.Equal(t, []string{"a", "b", "c", "d"}, metrics(r.GetInfo().PerformanceData))
+	assert.Equal(t, "'a'=1;~:10;~:100;; 'b'=50;~:10;~:100;; 'c'=1 'd'=200;~:10;~:100;;", r.performanceDataOutput())
+
+	r.SortPerformanceData(CompareDataPointsBySeverity)
+	assert.Equal(t, []string{"d", "b", "a", "c"}, metrics(r.GetInfo().PerformanceData))
+
+	r.SortPerformanceData(nil)
+	assert.Equal(t, []string{"c", "b", "a", "d"}, metrics(r.GetInfo().PerformanceData))
+}
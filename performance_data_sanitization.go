@@ -0,0 +1,54 @@
+package monitoringplugin
+
+import (
+	"strings"
+	"unicode"
+)
+
+// PerfDataSanitization specifies how the Response behaves if the metric or label of a PerformanceDataPoint contains
+// characters that are not allowed or cause problems in performance data.
+type PerfDataSanitization int
+
+const (
+	// PerfDataSanitizationReject returns an error and does not add the PerformanceDataPoint. This is the default.
+	PerfDataSanitizationReject PerfDataSanitization = iota
+	// PerfDataSanitizationReplace replaces invalid characters with an underscore.
+	PerfDataSanitizationReplace
+	// PerfDataSanitizationRemove removes invalid characters.
+	PerfDataSanitizationRemove
+)
+
+/*
+SetPerfDataSanitization sets how performance data points are handled whose metric or label contain '=', quotes or
+whitespace, which often happens if they come from external systems (e.g. SNMP ifDescr or JMX beans). Instead of
+rejecting the whole point, the invalid characters can be replaced or removed. The default is
+PerfDataSanitizationReject.
+Example:
+	Response.SetPerfDataSanitization(PerfDataSanitizationReplace)
+	err := Response.AddPerformanceDataPoint(NewPerformanceDataPoint("if 'GigabitEthernet0/1' in", 123))
+	//'if__GigabitEthernet0/1__in'=123
+*/
+func (r *Response) SetPerfDataSanitization(sanitization PerfDataSanitization) {
+	r.perfDataSanitization = sanitization
+}
+
+// sanitizePerformanceDataPoint returns a copy of the PerformanceDataPoint with a sanitized metric and label.
+func (r *Response) sanitizePerformanceDataPoint(point *PerformanceDataPoint) *PerformanceDataPoint {
+	sanitized := *point
+	sanitized.Metric = r.sanitizePerfDataName(point.Metric)
+	sanitized.Label = r.sanitizePerfDataName(point.Label)
+	return &sanitized
+}
+
+// sanitizePerfDataName replaces or removes the invalid characters of a metric or label.
+func (r *Response) sanitizePerfDataName(name string) string {
+	return strings.Map(func(c rune) rune {
+		if c != '=' && c != '\'' && c != '"' && !unicode.IsSpace(c) {
+			return c
+		}
+		if r.perfDataSanitization == PerfDataSanitizationReplace {
+			return '_'
+		}
+		return -1
+	}, name)
+}
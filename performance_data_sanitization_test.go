@@ -0,0 +1,21 @@
+package monitoringplugin
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestResponse_SetPerfDataSanitization(t *testing.T) {
+	r := NewResponse("checked")
+	assert.Error(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("a=b", 1)))
+
+	r.SetPerfDataSanitization(PerfDataSanitizationReplace)
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("if 'Gi0/1' in", 1)))
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("bean", 2).SetLabel("type=Memory")))
+
+	r.SetPerfDataSanitization(PerfDataSanitizationRemove)
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("a=\"b\" c", 3)))
+	assert.Error(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("'='", 4)))
+
+	assert.Equal(t, "OK: checked | 'if__Gi0/1__in'=1 'bean_type_Memory'=2 'abc'=3", r.GetInfo().RawOutput)
+}
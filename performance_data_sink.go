@@ -0,0 +1,119 @@
+package monitoringplugin
+
+import (
+	"bytes"
+	"github.com/pkg/errors"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// DefaultPerformanceDataTemplate is the default template of the PerformanceDataFileSink.
+// It is similar to the default service_perfdata_file_template of nagios.
+const DefaultPerformanceDataTemplate = "DATATYPE::SERVICEPERFDATA\tTIMET::{{.Timestamp.Unix}}\tHOSTNAME::{{.Host}}\t" +
+	"SERVICEDESC::{{.Service}}\tSERVICEPERFDATA::{{.PerformanceData}}\tSERVICESTATE::{{.Status}}\n"
+
+// PerformanceDataFileSink is a Sink that writes the performance data to a dedicated file or FIFO using a template.
+// The performance data is written even if printing performance data is deactivated (see Response.PrintPerformanceData).
+type PerformanceDataFileSink struct {
+	path      string
+	template  *template.Template
+	host      string
+	service   string
+	jsonLabel bool
+}
+
+// PerformanceDataTemplateData contains all data that can be used in the template of a PerformanceDataFileSink.
+// Timestamp is the latest timestamp of the performance data points (see PerformanceDataPoint.SetTimestamp) or the
+// current time if no timestamps are set. The timestamps of the individual points are available in Points.
+type PerformanceDataTemplateData struct {
+	Timestamp       time.Time
+	Host            string
+	Service         string
+	Status          Status
+	PerformanceData string
+	Points          []PerformanceDataPoint
+}
+
+/*
+NewPerformanceDataFileSink creates a new PerformanceDataFileSink writing to the given path using the
+DefaultPerformanceDataTemplate. Host and service default to the environment variables NAGIOS_HOSTNAME and
+NAGIOS_SERVICEDESC. If the path is a FIFO, writing blocks until it is opened for reading.
+Usage:
+	Response.AddSink(NewPerformanceDataFileSink("/var/spool/check_foo/perfdata"))
+*/
+func NewPerformanceDataFileSink(path string) *PerformanceDataFileSink {
+	return &PerformanceDataFileSink{
+		path:     path,
+		template: template.Must(template.New("perfdata").Parse(DefaultPerformanceDataTemplate)),
+		host:     os.Getenv("NAGIOS_HOSTNAME"),
+		service:  os.Getenv("NAGIOS_SERVICEDESC"),
+	}
+}
+
+// SetTemplate sets the text/template that is used to write the performance data (see PerformanceDataTemplateData).
+func (s *PerformanceDataFileSink) SetTemplate(tmpl string) error {
+	t, err := template.New("perfdata").Parse(tmpl)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse template")
+	}
+	s.template = t
+	return nil
+}
+
+// SetHost sets the host name that is passed to the template.
+func (s *PerformanceDataFileSink) SetHost(host string) *PerformanceDataFileSink {
+	s.host = host
+	return s
+}
+
+// SetService sets the service name that is passed to the template.
+func (s *PerformanceDataFileSink) SetService(service string) *PerformanceDataFileSink {
+	s.service = service
+	return s
+}
+
+// SetJSONLabel sets whether the performance data labels are written as JSON (see Response.SetPerformanceDataJSONLabel).
+func (s *PerformanceDataFileSink) SetJSONLabel(jsonLabel bool) *PerformanceDataFileSink {
+	s.jsonLabel = jsonLabel
+	return s
+}
+
+// Write writes the performance data of the result to the file.
+func (s *PerformanceDataFileSink) Write(info ResponseInfo) error {
+	var points []string
+	var timestamp time.Time
+	for _, point := range info.PerformanceData {
+		points = append(points, string(point.output(s.jsonLabel)))
+		if point.Timestamp.After(timestamp) {
+			timestamp = point.Timestamp
+		}
+	}
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+
+	var buffer bytes.Buffer
+	err := s.template.Execute(&buffer, PerformanceDataTemplateData{
+		Timestamp:       timestamp,
+		Host:            s.host,
+		Service:         s.service,
+		Status:          info.StatusCode,
+		PerformanceData: strings.Join(points, " "),
+		Points:          info.PerformanceData,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to execute template")
+	}
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return errors.Wrap(err, "failed to open performance data file")
+	}
+	if _, err := f.Write(buffer.Bytes()); err != nil {
+		_ = f.Close()
+		return errors.Wrap(err, "failed to write performance data file")
+	}
+	return errors.Wrap(f.Close(), "failed to close performance data file")
+}
@@ -0,0 +1,54 @@
+package monitoringplugin
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestPerformanceDataFileSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "monitoringplugin")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "perfdata")
+
+	r := NewResponse("checked")
+	r.PrintPerformanceData(false)
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("metric", 1).SetUnit("s")))
+	info := r.GetInfo()
+	assert.Equal(t, "OK: checked", info.RawOutput)
+
+	sink := NewPerformanceDataFileSink(path).SetHost("host").SetService("service")
+	assert.NoError(t, sink.Write(info))
+	content, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Regexp(t, regexp.MustCompile("^DATATYPE::SERVICEPERFDATA\tTIMET::[0-9]+\tHOSTNAME::host\tSERVICEDESC::service\tSERVICEPERFDATA::'metric'=1s\tSERVICESTATE::OK\n$"), string(content))
+
+	assert.Error(t, sink.SetTemplate("{{.Invalid"))
+	assert.NoError(t, sink.SetTemplate("{{.Host}} {{range .Points}}{{.Metric}}{{end}}\n"))
+	assert.NoError(t, sink.Write(info))
+	content, err = ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Regexp(t, regexp.MustCompile("\nhost metric\n$"), string(content))
+}
+
+func TestPerformanceDataFileSink_Timestamp(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "perfdata")
+	r := NewResponse("checked")
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("metric1", 1).SetTimestamp(time.Unix(1600000000, 0))))
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("metric2", 2).SetTimestamp(time.Unix(1600000060, 0))))
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("metric3", 3)))
+
+	sink := NewPerformanceDataFileSink(path)
+	assert.NoError(t, sink.SetTemplate("{{.Timestamp.Unix}}\n"))
+	assert.NoError(t, sink.Write(r.GetInfo()))
+	content, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "1600000060\n", string(content))
+}
@@ -0,0 +1,199 @@
+package monitoringplugin
+
+import (
+	"bytes"
+	"github.com/pkg/errors"
+	"io"
+	"os"
+	"strconv"
+)
+
+// performanceDataStream spools the output of streamed performance data points to a temporary file. Only the keys of
+// the points are kept in memory to detect duplicates. The spool file is created with the first streamed point.
+type performanceDataStream struct {
+	spool *os.File
+	keys  map[performanceDataPointKey]struct{}
+	err   error
+}
+
+/*
+EnablePerformanceDataStreaming activates the streaming mode for checks that emit tens of thousands of performance data
+points. In streaming mode, performance data points are rendered as soon as they are added and written to a temporary
+file instead of being accumulated in memory. OutputAndExit() copies them directly to the output writer after the
+output messages, which is allowed by the Monitoring Plugins Development Guidelines.
+Streamed points are checked against their thresholds, but they are neither contained in the ResponseInfo nor can they
+be sorted or limited with SetMaxPerformanceData. Duplicates can not be replaced, adding a point with DuplicateReplace
+returns an error. The label encoding and precision settings have to be made before the points are added. Streaming
+must be activated before the first performance data point is added. The spool file is released by Close() and Reset().
+Usage:
+	err := Response.EnablePerformanceDataStreaming()
+	if err != nil {
+		...
+	}
+	for _, port := range ports {
+		err = Response.AddPerformanceDataPoint(NewPerformanceDataPoint("in_octets", port.InOctets).SetLabel(port.Name))
+		...
+	}
+*/
+func (r *Response) EnablePerformanceDataStreaming() error {
+	if r.performanceDataStream != nil {
+		return nil
+	}
+	if len(r.performanceData) > 0 || len(r.derivedPerformanceData) > 0 {
+		return errors.New("performance data points were already added")
+	}
+	s := &performanceDataStream{keys: make(map[performanceDataPointKey]struct{})}
+	if err := s.open(); err != nil {
+		return err
+	}
+	r.performanceDataStream = s
+	return nil
+}
+
+/*
+Close releases the spool file of the streamed performance data (see EnablePerformanceDataStreaming). All streamed
+performance data points are removed, points that are added afterwards are spooled to a new file.
+OutputAndExit() closes the Response automatically.
+*/
+func (r *Response) Close() error {
+	if r.performanceDataStream == nil {
+		return nil
+	}
+	return r.performanceDataStream.close()
+}
+
+// open creates the spool file if it does not exist yet.
+func (s *performanceDataStream) open() error {
+	if s.err != nil {
+		return s.err
+	}
+	if s.spool != nil {
+		return nil
+	}
+	spool, err := os.CreateTemp("", "perfdata-")
+	if err != nil {
+		return errors.Wrap(err, "failed to create performance data spool file")
+	}
+	// the file is removed right away, it is deleted as soon as it is closed on systems that support it
+	_ = os.Remove(spool.Name())
+	s.spool = spool
+	return nil
+}
+
+/*
+streamPerformanceDataPoint validates the PerformanceDataPoint and writes it to the performance data stream. Duplicates
+are handled according to the given policy, except for DuplicateReplace, because streamed points can not be replaced.
+It returns the streamed PerformanceDataPoint, whose metric differs from the given one if an index was appended.
+*/
+func (r *Response) streamPerformanceDataPoint(point *PerformanceDataPoint, policy DuplicatePolicy) (
+	*PerformanceDataPoint, error) {
+	if err := point.Validate(); err != nil {
+		return nil, errors.Wrap(err, "given performance data point is not valid")
+	}
+	s := r.performanceDataStream
+	key := performanceDataPointKey{point.Metric, point.Label}
+	if _, ok := s.keys[key]; ok {
+		switch policy {
+		case DuplicateReplace:
+			return nil, point.validationError("metric", "streamed performance data points can not be replaced", nil)
+		case DuplicateSuffix:
+			suffixed := *point
+			for i := 2; ok; i++ {
+				suffixed.Metric = point.Metric + "_" + strconv.Itoa(i)
+				key = performanceDataPointKey{suffixed.Metric, suffixed.Label}
+				_, ok = s.keys[key]
+			}
+			point = &suffixed
+		default:
+			return nil, point.validationError("metric",
+				"a performance data point with the same metric and label does already exist", nil)
+		}
+	}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	s.keys[key] = struct{}{}
+	if len(r.filterPerformanceData([]PerformanceDataPoint{*point})) == 0 {
+		return point, nil
+	}
+	output := r.pointOutput(point)
+	if r.forceASCII {
+		output = toASCII(output)
+	}
+	if n, _ := s.spool.Seek(0, io.SeekCurrent); n > 0 {
+		output = append([]byte{' '}, output...)
+	}
+	_, err := s.spool.Write(output)
+	return point, errors.Wrap(err, "failed to write performance data spool file")
+}
+
+/*
+writeStreamedPerformanceData writes the streamed performance data to w. The given output is the output that was already
+written to w. If its last line already contains performance data, e.g. of sub-checks, the streamed points are appended
+to it, otherwise they are prefixed with the performance data separator.
+*/
+func (r *Response) writeStreamedPerformanceData(w io.Writer, output []byte) error {
+	s := r.performanceDataStream
+	if s == nil || !r.printPerformanceData {
+		return nil
+	}
+	if s.err != nil {
+		return s.err
+	}
+	if s.spool == nil {
+		return nil
+	}
+	size, err := s.spool.Seek(0, io.SeekEnd)
+	if err != nil || size == 0 {
+		return errors.Wrap(err, "failed to read performance data spool file")
+	}
+	separator := " | "
+	if bytes.IndexByte(output[bytes.LastIndexByte(output, '\n')+1:], '|') >= 0 {
+		separator = " "
+	}
+	if _, err := io.WriteString(w, separator); err != nil {
+		return err
+	}
+	if _, err := s.spool.Seek(0, io.SeekStart); err != nil {
+		return errors.Wrap(err, "failed to read performance data spool file")
+	}
+	_, err = io.Copy(w, s.spool)
+	return errors.Wrap(err, "failed to copy streamed performance data")
+}
+
+// close removes all streamed performance data points and closes the spool file.
+func (s *performanceDataStream) close() error {
+	s.keys = make(map[performanceDataPointKey]struct{})
+	s.err = nil
+	if s.spool == nil {
+		return nil
+	}
+	err := s.spool.Close()
+	s.spool = nil
+	return errors.Wrap(err, "failed to close performance data spool file")
+}
+
+// clone returns a copy of the stream with its own spool file, so the copies can not affect each other. If the spool
+// file can not be copied, the error is returned when the copy is used.
+func (s *performanceDataStream) clone() *performanceDataStream {
+	c := &performanceDataStream{keys: make(map[performanceDataPointKey]struct{}, len(s.keys)), err: s.err}
+	for key := range s.keys {
+		c.keys[key] = struct{}{}
+	}
+	if s.spool == nil || c.err != nil {
+		return c
+	}
+	if c.err = c.open(); c.err != nil {
+		return c
+	}
+	offset, err := s.spool.Seek(0, io.SeekCurrent)
+	if err == nil {
+		_, err = io.Copy(c.spool, io.NewSectionReader(s.spool, 0, offset))
+	}
+	if err != nil {
+		_ = c.spool.Close()
+		c.spool = nil
+		c.err = errors.Wrap(err, "failed to copy performance data spool file")
+	}
+	return c
+}
@@ -0,0 +1,87 @@
+package monitoringplugin
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestResponse_EnablePerformanceDataStreaming(t *testing.T) {
+	r := NewResponse("checked")
+	assert.NoError(t, r.EnablePerformanceDataStreaming())
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("in_octets", 10).SetLabel("eth0")))
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("in_octets", 20).SetLabel("eth1").
+		SetThresholds(NewThresholds(nil, 15, nil, nil))))
+	assert.Error(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("in_octets", 30).SetLabel("eth1")))
+	assert.Error(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("in_octets", 30).SetMax(20)))
+	assert.Empty(t, r.GetInfo().PerformanceData)
+
+	expected := "WARNING: in_octets (eth1) is outside of WARNING threshold | 'in_octets_eth0'=10 'in_octets_eth1'=20;~:15;;;"
+	output, exitCode := r.Output()
+	assert.Equal(t, expected+"\n", string(output))
+	assert.Equal(t, 1, exitCode)
+
+	var buffer bytes.Buffer
+	r.SetOutputWriter(&buffer)
+	r.SetExitFunc(func(int) {})
+	r.OutputAndExit()
+	assert.Equal(t, expected+"\n", buffer.String())
+
+	r.Reset()
+	assert.Equal(t, "OK: checked", r.String())
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("in_octets", 10).SetLabel("eth0")))
+	assert.Equal(t, "OK: checked | 'in_octets_eth0'=10", r.String())
+
+	r = NewResponse("checked")
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("load", 1)))
+	assert.Error(t, r.EnablePerformanceDataStreaming())
+}
+
+func TestResponse_EnablePerformanceDataStreaming_DuplicatePolicy(t *testing.T) {
+	r := NewResponse("checked")
+	assert.NoError(t, r.EnablePerformanceDataStreaming())
+	r.SetDuplicatePerformanceDataPolicy(DuplicateSuffix)
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("load", 1)))
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("load", 2)))
+	assert.Error(t, r.AddOrReplacePerformanceDataPoint(NewPerformanceDataPoint("load", 3)))
+	assert.Equal(t, "OK: checked | 'load'=1 'load_2'=2", r.String())
+}
+
+func TestResponse_EnablePerformanceDataStreaming_CloneAndClose(t *testing.T) {
+	r := NewResponse("checked")
+	assert.NoError(t, r.EnablePerformanceDataStreaming())
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("load", 1)))
+
+	clone := r.Clone()
+	assert.NoError(t, clone.AddPerformanceDataPoint(NewPerformanceDataPoint("users", 2)))
+	assert.Equal(t, "OK: checked | 'load'=1 'users'=2", clone.String())
+	assert.Equal(t, "OK: checked | 'load'=1", r.String())
+
+	clone.Reset()
+	assert.Equal(t, "OK: checked", clone.String())
+	assert.Equal(t, "OK: checked | 'load'=1", r.String())
+
+	assert.NoError(t, r.Close())
+	assert.Equal(t, "OK: checked", r.String())
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("load", 3)))
+	assert.Equal(t, "OK: checked | 'load'=3", r.String())
+	assert.NoError(t, r.Close())
+}
+
+func TestResponse_EnablePerformanceDataStreaming_SubCheckPerformanceData(t *testing.T) {
+	r := NewResponse("checked")
+	assert.NoError(t, r.EnablePerformanceDataStreaming())
+	sub := NewSubCheck()
+	assert.NoError(t, sub.AddPerformanceDataPoint(NewPerformanceDataPoint("usage", 2)))
+	r.AddSubCheck("disk1", sub)
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("load", 1)))
+
+	expected := "OK: checked\n\\_ [OK] disk1 | 'disk1::usage'=2 'load'=1"
+	assert.Equal(t, expected, r.String())
+
+	var buffer bytes.Buffer
+	r.SetOutputWriter(&buffer)
+	r.SetExitFunc(func(int) {})
+	r.OutputAndExit()
+	assert.Equal(t, expected+"\n", buffer.String())
+}
@@ -288,3 +288,224 @@ func TestResponse_SetPerformanceDataJsonLabel(t *testing.T) {
 		t.Error("there was no error when adding a performance data point with a metric, that already exists in performance data")
 	}
 }
+
+func TestPerformanceDataPoint_SetCounter(t *testing.T) {
+	p := NewPerformanceDataPoint("ifInOctets", 123456789).SetCounter()
+	if !p.Counter || p.Unit != "c" {
+		t.Error("SetCounter failed")
+	}
+	if err := p.Validate(); err != nil {
+		t.Error("valid counter is invalid: " + err.Error())
+	}
+	if string(p.output(false)) != "'ifInOctets'=123456789c" {
+		t.Error("output of counter is wrong: " + string(p.output(false)))
+	}
+
+	if err := NewPerformanceDataPoint("ifInOctets", 1).SetCounter().SetMin(0).SetMax(4294967295).Validate(); err != nil {
+		t.Error("valid counter with min and max is invalid: " + err.Error())
+	}
+	invalid := []*PerformanceDataPoint{
+		NewPerformanceDataPoint("ifInOctets", -1).SetCounter(),
+		NewPerformanceDataPoint("ifInOctets", 1).SetCounter().SetMin(1),
+		NewPerformanceDataPoint("ifInOctets", 1).SetCounter().SetUnit("B"),
+		NewPerformanceDataPoint("ifInOctets", 1).SetCounter().SetThresholds(NewThresholds(nil, 10, nil, 20)),
+	}
+	for _, point := range invalid {
+		if point.Validate() == nil {
+			t.Errorf("invalid counter %v is valid", *point)
+		}
+	}
+}
+
+func TestPerformanceDataPoint_SetFormat(t *testing.T) {
+	p := NewPerformanceDataPoint("load", 0.123456).SetMin(0).SetMax(1.5).SetFormat(2)
+	if string(p.output(false)) != "'load'=0.12;;;0.00;1.50" {
+		t.Error("output of formatted point is wrong: " + string(p.output(false)))
+	}
+
+	noExponent := map[interface{}]string{
+		float64(1e21):   "1000000000000000000000",
+		float32(1.5e10): "15000000000",
+		"2.5e3":         "2500",
+		uint64(1) << 63: "9223372036854775808",
+	}
+	for value, expected := range noExponent {
+		if res := formatNumber(value); res != expected {
+			t.Errorf("formatNumber(%v) returned %s, expected %s", value, res, expected)
+		}
+	}
+}
+
+func TestNewUnknownPerformanceDataPoint(t *testing.T) {
+	p := NewUnknownPerformanceDataPoint("temperature").SetUnit("C").SetMin(0)
+	if !p.IsValueUnknown() {
+		t.Error("value of unknown performance data point is not unknown")
+	}
+	if err := p.Validate(); err != nil {
+		t.Error("unknown performance data point is invalid: " + err.Error())
+	}
+	if string(p.output(false)) != "'temperature'=UC;;;0;" {
+		t.Error("output of unknown performance data point is wrong: " + string(p.output(false)))
+	}
+	if NewPerformanceDataPoint("temperature", 10).SetValueUnknown().Value != "U" {
+		t.Error("SetValueUnknown failed")
+	}
+
+	r := NewResponse("checked")
+	err := r.AddPerformanceDataPoint(NewUnknownPerformanceDataPoint("temperature").
+		SetThresholds(NewThresholds(nil, 35, nil, 40)))
+	if err != nil {
+		t.Error("failed to add unknown performance data point: " + err.Error())
+	}
+	if r.GetStatusCode() != OK {
+		t.Error("thresholds of unknown performance data point were checked")
+	}
+}
+
+func TestPerformanceDataPoint_InferMinMaxFromThresholds(t *testing.T) {
+	p := NewPerformanceDataPoint("temperature", 32).SetThresholds(NewThresholds(10, 35, 5, 40)).InferMinMaxFromThresholds()
+	if string(p.output(false)) != "'temperature'=32;10:35;5:40;5;40" {
+		t.Error("inferred min and max are wrong: " + string(p.output(false)))
+	}
+
+	p = NewPerformanceDataPoint("temperature", 50).SetMin(0).SetThresholds(NewThresholds(nil, 35, nil, nil)).
+		InferMinMaxFromThresholds()
+	if p.Min != 0 || p.Max != 50 {
+		t.Errorf("inferred min and max are wrong: %v, %v", p.Min, p.Max)
+	}
+	if err := p.Validate(); err != nil {
+		t.Error("point with inferred min and max is invalid: " + err.Error())
+	}
+
+	r := NewResponse("checked")
+	r.InferMinMaxFromThresholds(true)
+	p = NewPerformanceDataPoint("load", 1).SetThresholds(NewThresholds(nil, 5, nil, 10))
+	if err := r.AddPerformanceDataPoint(p); err != nil {
+		t.Error("failed to add performance data point: " + err.Error())
+	}
+	if p.Min != nil || p.Max != 10 {
+		t.Errorf("inferred min and max are wrong: %v, %v", p.Min, p.Max)
+	}
+}
+
+func TestResponse_AutoCheckThresholds(t *testing.T) {
+	r := NewResponse("checked")
+	err := r.AddPerformanceDataPointNoCheck(NewPerformanceDataPoint("load", 15).
+		SetThresholds(NewThresholds(nil, 10, nil, 20)))
+	if err != nil {
+		t.Error("failed to add performance data point: " + err.Error())
+	}
+	if r.GetStatusCode() != OK {
+		t.Error("thresholds were checked by AddPerformanceDataPointNoCheck")
+	}
+
+	r.AutoCheckThresholds(false)
+	err = r.AddPerformanceDataPoint(NewPerformanceDataPoint("memory", 25).SetThresholds(NewThresholds(nil, 10, nil, 20)))
+	if err != nil {
+		t.Error("failed to add performance data point: " + err.Error())
+	}
+	if r.GetStatusCode() != OK {
+		t.Error("thresholds were checked although automatic threshold checks are deactivated")
+	}
+
+	r.AutoCheckThresholds(true)
+	err = r.AddPerformanceDataPoint(NewPerformanceDataPoint("swap", 25).SetThresholds(NewThresholds(nil, 10, nil, 20)))
+	if err != nil {
+		t.Error("failed to add performance data point: " + err.Error())
+	}
+	if r.GetStatusCode() != CRITICAL {
+		t.Error("thresholds were not checked although automatic threshold checks are activated")
+	}
+}
+
+func TestResponse_SetMetricPrefix(t *testing.T) {
+	r := NewResponse("checked", WithMetricPrefix("ifstat_"))
+	p := NewPerformanceDataPoint("in_octets", 123).SetLabel("eth0")
+	if err := r.AddPerformanceDataPoint(p); err != nil {
+		t.Error("failed to add performance data point: " + err.Error())
+	}
+	if p.Metric != "in_octets" {
+		t.Error("metric of the added performance data point was modified: " + p.Metric)
+	}
+	if err := r.AddPerformanceDataPoint(NewPerformanceDataPoint("in_octets", 123).SetLabel("eth0")); err == nil {
+		t.Error("there was no error when adding a performance data point with a prefixed metric that already exists")
+	}
+
+	if output := r.performanceDataOutput(); output != "'ifstat_in_octets_eth0'=123" {
+		t.Error("output of prefixed performance data is wrong: " + output)
+	}
+	r.SetPerformanceDataJSONLabel(true)
+	if output := r.performanceDataOutput(); output != `'{"metric":"ifstat_in_octets","label":"eth0"}'=123` {
+		t.Error("output of prefixed performance data with JSON label is wrong: " + output)
+	}
+}
+
+func TestPerformanceDataPoint_SetOutputFunc(t *testing.T) {
+	p := NewPerformanceDataPoint("load", 0.5).SetThresholds(NewThresholds(nil, 0.4, nil, 1)).SetOutputFunc(
+		func(info DataPointInfo, jsonLabel bool) []byte {
+			return []byte(fmt.Sprintf("'%s'=%.3f;%s;%t", info.Metric, info.Value, info.Status, jsonLabel))
+		})
+	if output := string(p.output(false)); output != "'load'=0.500;WARNING;false" {
+		t.Error("output of point with output func is wrong: " + output)
+	}
+
+	r := NewResponse("checked")
+	r.SetPerformanceDataJSONLabel(true)
+	err := r.AddPerformanceDataPoint(NewPerformanceDataPoint("uptime", 10).SetOutputFunc(
+		func(info DataPointInfo, jsonLabel bool) []byte {
+			return append(info.DefaultOutput(jsonLabel), 's')
+		}))
+	if err != nil {
+		t.Error("failed to add performance data point: " + err.Error())
+	}
+	if output := r.performanceDataOutput(); output != `'{"metric":"uptime"}'=10s` {
+		t.Error("output of point with output func is wrong: " + output)
+	}
+}
+
+func TestResponse_SetMaxPrecision(t *testing.T) {
+	r := NewResponse("checked")
+	_ = r.AddPerformanceDataPoint(NewPerformanceDataPoint("load", 0.123456).SetMax(1.5))
+	_ = r.AddPerformanceDataPoint(NewPerformanceDataPoint("uptime", 1e9))
+	_ = r.AddPerformanceDataPoint(NewPerformanceDataPoint("ratio", 1e-20))
+	_ = r.AddPerformanceDataPoint(NewPerformanceDataPoint("fixed", 0.5).SetFormat(2))
+
+	if output := r.performanceDataOutput(); output !=
+		"'load'=0.123456;;;;1.5 'uptime'=1000000000 'ratio'=0.00000000000000000001 'fixed'=0.50" {
+		t.Error("output without max precision is wrong: " + output)
+	}
+	r.SetMaxPrecision(3)
+	if output := r.performanceDataOutput(); output != "'load'=0.123;;;;1.5 'uptime'=1000000000 'ratio'=0 'fixed'=0.50" {
+		t.Error("output with max precision is wrong: " + output)
+	}
+	r.SetMaxPrecision(0)
+	if output := r.performanceDataOutput(); output != "'load'=0;;;;2 'uptime'=1000000000 'ratio'=0 'fixed'=0.50" {
+		t.Error("output with max precision 0 is wrong: " + output)
+	}
+	r.SetMaxPrecision(-1)
+	if output := r.performanceDataOutput(); output !=
+		"'load'=0.123456;;;;1.5 'uptime'=1000000000 'ratio'=0.00000000000000000001 'fixed'=0.50" {
+		t.Error("output after disabling max precision is wrong: " + output)
+	}
+}
+
+func TestPerformanceDataPoint_WithLabel(t *testing.T) {
+	template := NewPerformanceDataPoint("cpu_usage", nil).SetUnit("%").SetMin(0).SetMax(100).
+		SetThresholds(NewThresholds(nil, 80, nil, 90))
+	r := NewResponse("checked")
+	for i, usage := range []int{10, 85} {
+		if err := r.AddPerformanceDataPoint(template.WithLabel(fmt.Sprint("cpu", i)).SetValue(usage)); err != nil {
+			t.Error("failed to add performance data point: " + err.Error())
+		}
+	}
+	if template.Label != "" || template.Value != nil {
+		t.Error("template was modified")
+	}
+	if output := r.performanceDataOutput(); output !=
+		"'cpu_usage_cpu0'=10%;~:80;~:90;0;100 'cpu_usage_cpu1'=85%;~:80;~:90;0;100" {
+		t.Error("output of points created with WithLabel is wrong: " + output)
+	}
+	if r.GetStatusCode() != WARNING {
+		t.Error("thresholds of points created with WithLabel were not checked")
+	}
+}
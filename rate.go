@@ -0,0 +1,140 @@
+package monitoringplugin
+
+import (
+	"github.com/inexio/go-monitoringplugin/v3/state"
+	"github.com/pkg/errors"
+	"math"
+	"time"
+)
+
+// CounterWrapBehavior specifies how AsRate handles counters that are smaller than their previous value, which happens
+// if a counter wrapped (common with SNMP interface counters) or was reset.
+type CounterWrapBehavior int
+
+const (
+	// CounterWrapCorrect assumes that the counter wrapped and corrects the delta. The counter wraps at its max + 1 if
+	// a max is set, otherwise at 2^32 or 2^64. A wrap is only plausible if the corrected delta is less than half of the
+	// range of the counter, otherwise the counter is assumed to be reset and the sample is treated as invalid.
+	CounterWrapCorrect CounterWrapBehavior = iota
+	// CounterWrapInvalid treats the sample as invalid, so no rate is calculated.
+	CounterWrapInvalid
+)
+
+// StateStore persists data between invocations of a check plugin (see state.Store and state.FileStore).
+type StateStore = state.Store
+
+// rateSample is the value of a PerformanceDataPoint at a specific time that is persisted to calculate rates.
+type rateSample struct {
+	Value     float64   `json:"value"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// counterWrapModuli returns the values at which the counter may wrap to 0, i.e. its max + 1 if a max is set, otherwise
+// 2^32 (if the previous value fits into 32 bits) and 2^64.
+func (p *PerformanceDataPoint) counterWrapModuli(previous float64) []float64 {
+	if p.Max != nil {
+		if max, err := parseFloat(p.Max); err == nil && max >= previous {
+			return []float64{max + 1}
+		}
+	}
+	if previous <= math.MaxUint32 {
+		return []float64{math.MaxUint32 + 1, math.MaxUint64 + 1}
+	}
+	return []float64{math.MaxUint64 + 1}
+}
+
+// correctCounterWrap returns the delta of a wrapped counter. It returns false if no wrap is plausible, i.e. the
+// corrected delta is not less than half of the range of the counter, which means that the counter was reset.
+func (p *PerformanceDataPoint) correctCounterWrap(previous, delta float64) (float64, bool) {
+	for _, modulus := range p.counterWrapModuli(previous) {
+		if corrected := delta + modulus; corrected < modulus/2 {
+			return corrected, true
+		}
+	}
+	return 0, false
+}
+
+// rateStateKey returns the key that is used to persist the samples of the PerformanceDataPoint.
+func (p *PerformanceDataPoint) rateStateKey() string {
+	key := "rate:" + p.Metric
+	if p.Label != "" {
+		key += "_" + p.Label
+	}
+	return key
+}
+
+/*
+AsRate replaces the value of the PerformanceDataPoint with its per-second rate since the previous invocation of the
+check plugin, like check_snmp's --rate. The previous value and timestamp are loaded from the state store and the
+current sample is persisted for the next invocation. Thresholds are applied to the rate instead of the raw value.
+A counter (see SetCounter()) becomes a regular PerformanceDataPoint without unit and max, and counter wraps are
+corrected (see CounterWrapCorrect).
+If there is no previous sample (e.g. on the first invocation), the value is not changed and false is returned. In that
+case the PerformanceDataPoint should not be added to the response.
+Usage:
+	point := NewPerformanceDataPoint("ifInOctets", octets).SetCounter().SetThresholds(NewThresholds(nil, 1e6, nil, 1e7))
+	ok, err := point.AsRate(store)
+	if err != nil {
+		...
+	}
+	if ok {
+		err = Response.AddPerformanceDataPoint(point)
+		...
+	}
+*/
+func (p *PerformanceDataPoint) AsRate(store StateStore) (bool, error) {
+	return p.asRate(store, CounterWrapCorrect)
+}
+
+/*
+AsRateWithCounterWrap works like AsRate(StateStore), but handles counter wraps with the given behavior.
+If the sample is invalid, the value is not changed and false is returned, like on the first invocation.
+*/
+func (p *PerformanceDataPoint) AsRateWithCounterWrap(store StateStore, behavior CounterWrapBehavior) (bool, error) {
+	return p.asRate(store, behavior)
+}
+
+func (p *PerformanceDataPoint) asRate(store StateStore, behavior CounterWrapBehavior) (bool, error) {
+	value, err := parseFloat(p.Value)
+	if err != nil {
+		return false, errors.Wrap(err, "can't parse value")
+	}
+	current := rateSample{
+		Value:     value,
+		Timestamp: time.Now(),
+	}
+
+	key := p.rateStateKey()
+	var previous rateSample
+	found, err := store.Load(key, &previous)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to load previous sample")
+	}
+	if err := store.Save(key, current); err != nil {
+		return false, errors.Wrap(err, "failed to save current sample")
+	}
+
+	elapsed := current.Timestamp.Sub(previous.Timestamp).Seconds()
+	if !found || elapsed <= 0 {
+		return false, nil
+	}
+
+	delta := current.Value - previous.Value
+	if p.Counter && delta < 0 {
+		if behavior == CounterWrapInvalid {
+			return false, nil
+		}
+		var ok bool
+		if delta, ok = p.correctCounterWrap(previous.Value, delta); !ok {
+			return false, nil
+		}
+	}
+
+	p.Value = delta / elapsed
+	if p.Counter {
+		p.Counter = false
+		p.Unit = ""
+		p.Max = nil
+	}
+	return true, nil
+}
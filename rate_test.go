@@ -0,0 +1,152 @@
+package monitoringplugin
+
+import (
+	"encoding/json"
+	"github.com/inexio/go-monitoringplugin/v3/state"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var _ StateStore = state.NewFileStore("check")
+
+// memoryStateStore is a StateStore that keeps the JSON encoded data in memory.
+type memoryStateStore map[string][]byte
+
+func (s memoryStateStore) Load(key string, v interface{}) (bool, error) {
+	data, ok := s[key]
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(data, v)
+}
+
+func (s memoryStateStore) Save(key string, v interface{}) error {
+	data, err := json.Marshal(v)
+	s[key] = data
+	return err
+}
+
+type failingStateStore struct{}
+
+func (failingStateStore) Load(string, interface{}) (bool, error) {
+	return false, errors.New("disk failure")
+}
+
+func (failingStateStore) Save(string, interface{}) error {
+	return errors.New("disk failure")
+}
+
+func TestPerformanceDataPoint_AsRate(t *testing.T) {
+	store := make(memoryStateStore)
+	point := NewPerformanceDataPoint("ifInOctets", 1000).SetLabel("eth0").SetCounter()
+	ok, err := point.AsRate(store)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, 1000, point.Value)
+	assert.Contains(t, store, "rate:ifInOctets_eth0")
+
+	assert.NoError(t, store.Save("rate:ifInOctets_eth0", rateSample{Value: 1000, Timestamp: time.Now().Add(-10 * time.Second)}))
+	point = NewPerformanceDataPoint("ifInOctets", 6000).SetLabel("eth0").SetCounter().
+		SetThresholds(NewThresholds(nil, 400, nil, 1000))
+	ok, err = point.AsRate(store)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.InDelta(t, 500, point.Value, 1)
+	assert.False(t, point.Counter)
+	assert.Equal(t, "", point.Unit)
+
+	r := NewResponse("checked")
+	assert.NoError(t, r.AddPerformanceDataPoint(point))
+	assert.Equal(t, WARNING, r.GetStatusCode())
+}
+
+func TestPerformanceDataPoint_AsRate_Errors(t *testing.T) {
+	_, err := NewPerformanceDataPoint("metric", "abc").AsRate(make(memoryStateStore))
+	assert.Error(t, err)
+	_, err = NewPerformanceDataPoint("metric", 1).AsRate(failingStateStore{})
+	assert.Error(t, err)
+}
+
+func TestPerformanceDataPoint_AsRate_FileStore(t *testing.T) {
+	store := state.NewFileStoreAt(filepath.Join(t.TempDir(), "check.json"))
+	ok, err := NewPerformanceDataPoint("ifInOctets", 1000).SetCounter().AsRate(store)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = NewPerformanceDataPoint("ifInOctets", 2000).SetCounter().AsRate(store)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestPerformanceDataPoint_AsRate_CounterWrap(t *testing.T) {
+	store := make(memoryStateStore)
+	tenSecondsAgo := func(value float64) rateSample {
+		return rateSample{Value: value, Timestamp: time.Now().Add(-10 * time.Second)}
+	}
+
+	// 32-bit counter
+	assert.NoError(t, store.Save("rate:ifInOctets", tenSecondsAgo(math.MaxUint32-999)))
+	point := NewPerformanceDataPoint("ifInOctets", 4000).SetCounter()
+	ok, err := point.AsRate(store)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.InDelta(t, 500, point.Value, 1)
+
+	// 64-bit counter
+	assert.NoError(t, store.Save("rate:ifHCInOctets", tenSecondsAgo(math.MaxUint64-1e6)))
+	point = NewPerformanceDataPoint("ifHCInOctets", 4e6).SetCounter()
+	ok, err = point.AsRate(store)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.InDelta(t, 5e5, point.Value, 1e4)
+
+	// counter with max
+	assert.NoError(t, store.Save("rate:sequence", tenSecondsAgo(9500)))
+	point = NewPerformanceDataPoint("sequence", 4000).SetCounter().SetMax(9999)
+	ok, err = point.AsRate(store)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.InDelta(t, 450, point.Value, 1)
+	assert.Nil(t, point.Max)
+
+	// reset of a 64-bit counter above 32 bits
+	assert.NoError(t, store.Save("rate:ifHCOutOctets", tenSecondsAgo(1e12)))
+	point = NewPerformanceDataPoint("ifHCOutOctets", 1000).SetCounter()
+	ok, err = point.AsRate(store)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, 1000, point.Value)
+
+	// reset of a counter with a small previous value
+	assert.NoError(t, store.Save("rate:ifHCOutOctets", tenSecondsAgo(5000)))
+	point = NewPerformanceDataPoint("ifHCOutOctets", 1000).SetCounter()
+	ok, err = point.AsRate(store)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// reset of a counter with max
+	assert.NoError(t, store.Save("rate:sequence", tenSecondsAgo(3000)))
+	point = NewPerformanceDataPoint("sequence", 10).SetCounter().SetMax(9999)
+	ok, err = point.AsRate(store)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// invalid sample
+	assert.NoError(t, store.Save("rate:ifInOctets", tenSecondsAgo(5000)))
+	point = NewPerformanceDataPoint("ifInOctets", 4000).SetCounter()
+	ok, err = point.AsRateWithCounterWrap(store, CounterWrapInvalid)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, 4000, point.Value)
+
+	// gauges may decrease
+	assert.NoError(t, store.Save("rate:temperature", tenSecondsAgo(30)))
+	point = NewPerformanceDataPoint("temperature", 25)
+	ok, err = point.AsRateWithCounterWrap(store, CounterWrapInvalid)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.InDelta(t, -0.5, point.Value, 0.01)
+}
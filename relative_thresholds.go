@@ -0,0 +1,70 @@
+package monitoringplugin
+
+import (
+	"strconv"
+)
+
+// relativeThresholds contains the warning and critical thresholds in percent of the max of a PerformanceDataPoint.
+type relativeThresholds struct {
+	warning  float64
+	critical float64
+}
+
+/*
+SetRelativeThresholds sets the warning and critical thresholds in percent of the max of the performance data point.
+The absolute thresholds are computed from the max, which must be set. Violation messages contain both the percentage
+and the absolute threshold.
+Usage:
+	PerformanceDataPoint := NewPerformanceDataPoint("disk_usage", 850).SetUnit("GB").SetMax(1000).
+		SetRelativeThresholds(80, 90)
+	//'disk_usage'=850GB;~:800;~:900;;1000
+	//WARNING: disk_usage is outside of WARNING threshold (80% of max = 800GB)
+*/
+func (p *PerformanceDataPoint) SetRelativeThresholds(warnPct, critPct float64) *PerformanceDataPoint {
+	p.relativeThresholds = &relativeThresholds{
+		warning:  warnPct,
+		critical: critPct,
+	}
+	p.applyRelativeThresholds()
+	return p
+}
+
+// applyRelativeThresholds computes the absolute thresholds from the relative thresholds and the max.
+func (p *PerformanceDataPoint) applyRelativeThresholds() {
+	if p.relativeThresholds == nil || p.Max == nil {
+		return
+	}
+	max, err := parseFloat(p.Max)
+	if err != nil {
+		return
+	}
+	p.Thresholds = NewThresholds(nil, max*p.relativeThresholds.warning/100,
+		nil, max*p.relativeThresholds.critical/100)
+}
+
+// validateRelativeThresholds validates the properties that are specific to relative thresholds.
+func (p *PerformanceDataPoint) validateRelativeThresholds() error {
+	if p.relativeThresholds == nil {
+		return nil
+	}
+	if p.Max == nil {
+		return p.validationError("max", "relative thresholds require a max", nil)
+	}
+	if p.relativeThresholds.warning < 0 || p.relativeThresholds.critical < 0 {
+		return p.validationError("thresholds", "relative thresholds cannot be negative", nil)
+	}
+	return nil
+}
+
+// relativeThresholdText returns the relative and absolute threshold that was violated with the given status, e.g.
+// " (80% of max = 800GB)". If no relative thresholds are set, an empty string is returned.
+func (p *PerformanceDataPoint) relativeThresholdText(status Status) string {
+	if p.relativeThresholds == nil {
+		return ""
+	}
+	pct, threshold := p.relativeThresholds.warning, p.Thresholds.WarningMax
+	if status == CRITICAL {
+		pct, threshold = p.relativeThresholds.critical, p.Thresholds.CriticalMax
+	}
+	return " (" + strconv.FormatFloat(pct, 'f', -1, 64) + "% of max = " + p.formatNumber(threshold) + p.Unit + ")"
+}
@@ -0,0 +1,24 @@
+package monitoringplugin
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPerformanceDataPoint_SetRelativeThresholds(t *testing.T) {
+	p := NewPerformanceDataPoint("disk_usage", 850).SetUnit("GB").SetMax(1000).SetRelativeThresholds(80, 90)
+	assert.NoError(t, p.Validate())
+	assert.Equal(t, "'disk_usage'=850GB;~:800;~:900;;1000", string(p.output(false)))
+
+	r := NewResponse("checked")
+	assert.NoError(t, r.AddPerformanceDataPoint(p))
+	assert.Equal(t, WARNING, r.GetStatusCode())
+	assert.Equal(t, "disk_usage is outside of WARNING threshold (80% of max = 800GB)", r.outputMessages[0].Message)
+
+	p = NewPerformanceDataPoint("disk_usage", 950).SetRelativeThresholds(80, 90.5).SetMax(1000)
+	assert.Equal(t, "'disk_usage'=950;~:800;~:905;;1000", string(p.output(false)))
+	assert.Equal(t, " (90.5% of max = 905)", p.relativeThresholdText(CRITICAL))
+
+	assert.Error(t, NewPerformanceDataPoint("disk_usage", 850).SetRelativeThresholds(80, 90).Validate())
+	assert.Error(t, NewPerformanceDataPoint("disk_usage", 850).SetMax(1000).SetRelativeThresholds(-1, 90).Validate())
+}
@@ -3,22 +3,20 @@ package monitoringplugin
 
 import (
 	"bytes"
+	"encoding/json"
+	stderrors "errors"
 	"fmt"
 	"github.com/pkg/errors"
+	"io"
+	"log/slog"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
-)
-
-const (
-	// OK check plugin status = OK
-	OK = 0
-	// WARNING check plugin status = WARNING
-	WARNING = 1
-	// CRITICAL check plugin status = CRITICAL
-	CRITICAL = 2
-	// UNKNOWN check plugin status = UNKNOWN
-	UNKNOWN = 3
+	"sync/atomic"
+	"text/template"
+	"time"
+	"unicode/utf8"
 )
 
 // InvalidCharacterBehavior specifies how the monitoringplugin should behave if an invalid character is found in the
@@ -39,47 +37,258 @@ const (
 	// InvalidCharacterReplaceWithErrorAndSetUNKNOWN replaces the whole message with an error message if an invalid character is found.
 	// Also sets the status code to UNKNOWN.
 	InvalidCharacterReplaceWithErrorAndSetUNKNOWN
+	// InvalidCharacterCallback passes a message with an invalid character to a user defined callback.
+	// Only valid if a callback is set (see Response.SetInvalidCharacterCallback).
+	InvalidCharacterCallback
 )
 
-// OutputMessage represents a message of the response. It contains a message and a status code.
+// InvalidCharacterCallbackFunc is called for every message that contains an invalid character if the
+// InvalidCharacterCallback behavior is set. It returns the sanitized message and whether the message should be kept.
+// Invalid characters that are still contained in the returned message are removed.
+type InvalidCharacterCallbackFunc func(message OutputMessage) (OutputMessage, bool)
+
+// OutputMessage represents a message of the response. It contains a message, a status code and optional fields.
 type OutputMessage struct {
-	Status  int    `yaml:"status" json:"status" xml:"status"`
-	Message string `yaml:"message" json:"message" xml:"message"`
+	Status  Status  `yaml:"status" json:"status" xml:"status"`
+	Message string  `yaml:"message" json:"message" xml:"message"`
+	Fields  []Field `yaml:"fields,omitempty" json:"fields,omitempty" xml:"fields>field,omitempty"`
+
+	// derived is true if the message results from a derived or lazy performance data point.
+	derived bool
+}
+
+// outputMessageEncoding is used to marshal an OutputMessage together with the text representation of its status.
+type outputMessageEncoding struct {
+	Status     int                    `yaml:"status" json:"status"`
+	StatusText string                 `yaml:"status_text" json:"status_text"`
+	Message    string                 `yaml:"message" json:"message"`
+	Fields     map[string]interface{} `yaml:"fields,omitempty" json:"fields,omitempty"`
+}
+
+func (m OutputMessage) encoding() outputMessageEncoding {
+	return outputMessageEncoding{
+		Status:     int(m.Status),
+		StatusText: m.Status.String(),
+		Message:    m.Message,
+		Fields:     fieldsMap(m.Fields),
+	}
+}
+
+// text returns the message followed by its fields in the format "key=value".
+func (m OutputMessage) text() string {
+	if len(m.Fields) == 0 {
+		return m.Message
+	}
+	return m.Message + " " + fieldsText(m.Fields)
+}
+
+// MarshalJSON implements the json.Marshaler interface. Besides the numeric status, the status text is included.
+func (m OutputMessage) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.encoding())
+}
+
+// MarshalYAML implements the yaml.Marshaler interface. Besides the numeric status, the status text is included.
+func (m OutputMessage) MarshalYAML() (interface{}, error) {
+	return m.encoding(), nil
 }
 
 // Response is the main type that is responsible for the check plugin Response.
 // It stores the current status code, output messages, performance data and the output message delimiter.
 type Response struct {
-	statusCode                  int
+	statusCode                  Status
 	defaultOkMessage            string
+	initialDefaultOkMessage     string
+	defaultOkMessageFunc        func() string
+	summary                     string
+	statusSummary               bool
+	summaryStrategy             SummaryStrategy
+	autoOkMessage               bool
+	hideDefaultOkMessage        bool
+	hideRedundantOkMessage      bool
 	outputMessages              []OutputMessage
+	silentStatusUpdates         []Status
+	longOutput                  []string
+	infoMessages                []string
+	violations                  []ViolationInfo
+	verbose                     bool
 	performanceData             performanceData
+	performanceDataSequence     int
+	derivedPerformanceData      []*PerformanceDataPoint
+	derived                     derivedState
 	outputDelimiter             string
-	performanceDataJSONLabel    bool
+	labelEncoding               LabelEncoding
+	labelKey                    string
+	compactLabelQuoting         bool
+	maxPrecision                int
+	hasMaxPrecision             bool
+	metricPrefix                string
+	perfDataSanitization        PerfDataSanitization
+	performanceDataStream       *performanceDataStream
 	printPerformanceData        bool
+	performanceDataCompare      func(a, b DataPointInfo) int
+	maxPerformanceData          int
+	inferMinMax                 bool
+	autoCheckThresholds         bool
+	duplicatePolicy             DuplicatePolicy
+	maxPerformanceDataLength    int
+	performanceDataInclude      []metricPattern
+	performanceDataExclude      []metricPattern
+	strictUnits                 bool
+	forceASCII                  bool
+	checkMetadata               *CheckMetadata
+	printCheckMetadata          bool
 	sortOutputMessagesByStatus  bool
+	prefixMessagesWithStatus    bool
+	quiet                       bool
+	dryRun                      bool
+	ignoreSIGPIPE               bool
+	printStackTraceOnPanic      bool
+	maintenanceMode             bool
+	maintenanceMaxStatus        Status
+	dependentStatus             bool
+	maxStatus                   Status
+	hasMaxStatus                bool
+	forcedStatus                Status
+	statusForced                bool
+	maxMessageLength            int
+	maxMessages                 int
+	droppedMessages             map[Status]int
+	messageDeduplication        bool
+	maxLineWidth                int
+	indent                      string
+	indentContinuationLines     bool
 	invalidCharacterBehaviour   InvalidCharacterBehavior
 	invalidCharacterReplaceChar string
+	invalidCharacterCallback    InvalidCharacterCallbackFunc
+	errorChainDepth             int
+	includeErrorType            bool
+	onStatusChange              []func(old, new Status, trigger OutputMessage)
+	sinks                       []Sink
+	exitHooks                   []func(info ResponseInfo)
+	startTime                   time.Time
+	outputWriter                io.Writer
+	exitFunc                    func(int)
+	finalized                   int32
+	exited                      bool
+	modifications               int32
+	signalState                 int32
+	lateMutationBehavior        LateMutationBehavior
+	exitCodeMapping             map[Status]int
+	statusLabels                map[Status]string
+	outputTemplate              *template.Template
+	thresholdMessageTemplate    *template.Template
+	logger                      *slog.Logger
+	subChecks                   []namedSubCheck
+	subCheckPolicy              SubCheckPolicy
+	subCheckQuorum              int
 }
 
 /*
 NewResponse creates a new Response and sets the default OK message to the given string.
 The default OK message will be displayed together with the other output messages, but only
 if the status is still OK when the check exits.
+The Response can be configured with options, which are applied in the given order.
+Example:
+	Response := NewResponse("everything checked!", WithDelimiter(" / "), WithoutPerformanceData())
 */
-func NewResponse(defaultOkMessage string) *Response {
+func NewResponse(defaultOkMessage string, opts ...Option) *Response {
 	response := &Response{
 		statusCode:                 OK,
 		defaultOkMessage:           defaultOkMessage,
+		initialDefaultOkMessage:    defaultOkMessage,
 		outputDelimiter:            "\n",
+		indent:                     "    ",
 		printPerformanceData:       true,
+		autoCheckThresholds:        true,
 		sortOutputMessagesByStatus: true,
 		invalidCharacterBehaviour:  InvalidCharacterRemove,
+		lateMutationBehavior:       LateMutationError,
+		subCheckPolicy:             SubCheckPolicyWorst,
+		startTime:                  time.Now(),
+		outputWriter:               os.Stdout,
+		exitFunc:                   os.Exit,
 	}
 	response.performanceData = make(performanceData)
+	for _, opt := range opts {
+		opt(response)
+	}
 	return response
 }
 
+/*
+Reset resets the status, output messages, info messages, summary, long output, performance data and sub-checks of the
+Response, while keeping its configuration (delimiter, invalid character behavior, sinks, etc.).
+This allows reusing a Response when the same check is evaluated repeatedly, e.g. in a daemon.
+*/
+func (r *Response) Reset() {
+	r.statusCode = OK
+	r.defaultOkMessage = r.initialDefaultOkMessage
+	r.summary = ""
+	r.outputMessages = nil
+	r.silentStatusUpdates = nil
+	r.longOutput = nil
+	r.infoMessages = nil
+	r.violations = nil
+	r.droppedMessages = nil
+	r.performanceData = make(performanceData)
+	r.derivedPerformanceData = nil
+	r.derived = derivedState{}
+	if r.performanceDataStream != nil {
+		_ = r.performanceDataStream.close()
+	}
+	r.subChecks = nil
+	r.startTime = time.Now()
+	atomic.StoreInt32(&r.finalized, 0)
+	r.exited = false
+}
+
+// Clone returns a copy of the Response including its configuration and current state.
+// Changes to the copy do not affect the original Response. Sub-checks are shared between the copies, streamed
+// performance data (see EnablePerformanceDataStreaming) is copied to a spool file of its own.
+func (r *Response) Clone() *Response {
+	clone := *r
+	clone.modifications = 0
+	clone.signalState = signalNone
+	clone.outputMessages = append([]OutputMessage(nil), r.outputMessages...)
+	clone.silentStatusUpdates = append([]Status(nil), r.silentStatusUpdates...)
+	clone.longOutput = append([]string(nil), r.longOutput...)
+	clone.infoMessages = append([]string(nil), r.infoMessages...)
+	clone.violations = append([]ViolationInfo(nil), r.violations...)
+	clone.performanceData = make(performanceData, len(r.performanceData))
+	for key, point := range r.performanceData {
+		clone.performanceData[key] = point
+	}
+	clone.subChecks = append([]namedSubCheck(nil), r.subChecks...)
+	clone.derivedPerformanceData = nil
+	for _, point := range r.derivedPerformanceData {
+		derived := *point
+		clone.derivedPerformanceData = append(clone.derivedPerformanceData, &derived)
+	}
+	clone.derived = derivedState{
+		points:     append([]derivedEntry(nil), r.derived.points...),
+		violations: append([]ViolationInfo(nil), r.derived.violations...),
+	}
+	if r.performanceDataStream != nil {
+		clone.performanceDataStream = r.performanceDataStream.clone()
+	}
+	clone.sinks = append([]Sink(nil), r.sinks...)
+	clone.exitHooks = append(r.exitHooks[:0:0], r.exitHooks...)
+	clone.onStatusChange = append(r.onStatusChange[:0:0], r.onStatusChange...)
+	if r.exitCodeMapping != nil {
+		clone.SetExitCodeMapping(r.exitCodeMapping)
+	}
+	if r.statusLabels != nil {
+		clone.SetStatusLabels(r.statusLabels)
+	}
+	if r.droppedMessages != nil {
+		clone.droppedMessages = make(map[Status]int, len(r.droppedMessages))
+		for status, n := range r.droppedMessages {
+			clone.droppedMessages[status] = n
+		}
+	}
+	return &clone
+}
+
 /*
 AddPerformanceDataPoint adds a PerformanceDataPoint to the performanceData map,
 using performanceData.add(*PerformanceDataPoint).
@@ -90,45 +299,330 @@ Usage:
 	}
 */
 func (r *Response) AddPerformanceDataPoint(point *PerformanceDataPoint) error {
-	err := r.performanceData.add(point)
+	return r.addPerformanceDataPoint(point, r.autoCheckThresholds, r.duplicatePolicy)
+}
+
+/*
+AddPerformanceDataPointNoCheck works like AddPerformanceDataPoint(*PerformanceDataPoint), but does not check the value
+against the thresholds of the PerformanceDataPoint, so the thresholds are only used for graph annotations.
+*/
+func (r *Response) AddPerformanceDataPointNoCheck(point *PerformanceDataPoint) error {
+	return r.addPerformanceDataPoint(point, false, r.duplicatePolicy)
+}
+
+/*
+AddPerformanceDataPoints adds all given PerformanceDataPoints like AddPerformanceDataPoint(*PerformanceDataPoint).
+Invalid points are skipped, while all valid points are added. The returned error joins the errors of all rejected
+points, it is nil if all points were added.
+Usage:
+	err := Response.AddPerformanceDataPoints(
+		NewPerformanceDataPoint("load1", 0.5),
+		NewPerformanceDataPoint("load5", 0.7),
+	)
 	if err != nil {
+		...
+	}
+*/
+func (r *Response) AddPerformanceDataPoints(points ...*PerformanceDataPoint) error {
+	var errs []error
+	for i, point := range points {
+		if point == nil {
+			errs = append(errs, errors.Errorf("performance data point %d is nil", i))
+			continue
+		}
+		if err := r.AddPerformanceDataPoint(point); err != nil {
+			errs = append(errs, errors.Wrapf(err, "performance data point '%s'", point.name()))
+		}
+	}
+	return stderrors.Join(errs...)
+}
+
+func (r *Response) addPerformanceDataPoint(point *PerformanceDataPoint, checkThresholds bool,
+	duplicatePolicy DuplicatePolicy) error {
+	defer r.beginModification()()
+	if err := r.checkFinalized(); err != nil {
 		return errors.Wrap(err, "failed to add performance data point")
 	}
+	if point.derive != nil || point.lazy != nil {
+		if r.performanceDataStream != nil {
+			return errors.New("derived and lazy performance data points can not be streamed")
+		}
+		r.addDerivedPerformanceDataPoint(point)
+		return nil
+	}
+	_, err := r.storePerformanceDataPoint(point, checkThresholds, duplicatePolicy)
+	return err
+}
 
-	if !point.Thresholds.IsEmpty() {
-		name := point.Metric
-		if point.Label != "" {
-			name += " (" + point.Label + ")"
+// storePerformanceDataPoint adds a PerformanceDataPoint without checking whether the Response is finalized and
+// returns the stored PerformanceDataPoint.
+func (r *Response) storePerformanceDataPoint(point *PerformanceDataPoint, checkThresholds bool,
+	duplicatePolicy DuplicatePolicy) (*PerformanceDataPoint, error) {
+	if r.inferMinMax {
+		point.InferMinMaxFromThresholds()
+	}
+	if r.metricPrefix != "" {
+		prefixed := *point
+		prefixed.Metric = r.metricPrefix + point.Metric
+		point = &prefixed
+	}
+	if r.perfDataSanitization != PerfDataSanitizationReject {
+		point = r.sanitizePerformanceDataPoint(point)
+	}
+	if r.strictUnits {
+		if err := point.validateUnit(); err != nil {
+			return nil, errors.Wrap(err, "given performance data point is not valid")
 		}
-		err = r.CheckThresholds(point.Thresholds, point.Value, name)
+	}
+	point.sequence = r.performanceDataSequence
+	r.performanceDataSequence++
+	var err error
+	if r.performanceDataStream != nil {
+		point, err = r.streamPerformanceDataPoint(point, duplicatePolicy)
+	} else {
+		point, err = r.performanceData.addWithPolicy(point, duplicatePolicy)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to add performance data point")
+	}
+
+	if checkThresholds && !point.Thresholds.IsEmpty() && !point.IsValueUnknown() {
+		err = r.checkThresholds(point.Thresholds, point.Value, point.name(), point.Unit, point.relativeThresholdText)
 		if err != nil {
-			return errors.Wrap(err, "failed to check thresholds")
+			return point, errors.Wrap(err, "failed to check thresholds")
 		}
 	}
 
-	return nil
+	return point, nil
+}
+
+/*
+GetPerformanceDataPoint returns a copy of the PerformanceDataPoint with the given metric and label, so post-processing
+code can inspect what was collected. Changes to the copy can be applied with AddOrReplacePerformanceDataPoint. If there
+is no such PerformanceDataPoint, nil is returned.
+Usage:
+	if point := Response.GetPerformanceDataPoint("memory_usage", ""); point != nil {
+		err := Response.AddOrReplacePerformanceDataPoint(point.SetUnit("%"))
+		...
+	}
+*/
+func (r *Response) GetPerformanceDataPoint(metric, label string) *PerformanceDataPoint {
+	point, ok := r.performanceData[performanceDataPointKey{metric, label}]
+	if !ok {
+		return nil
+	}
+	return &point
+}
+
+// RemovePerformanceDataPoint removes the PerformanceDataPoint with the given metric and label. Status updates that
+// resulted from its thresholds are kept. It returns false if there is no such PerformanceDataPoint.
+func (r *Response) RemovePerformanceDataPoint(metric, label string) bool {
+	key := performanceDataPointKey{metric, label}
+	if _, ok := r.performanceData[key]; !ok {
+		return false
+	}
+	delete(r.performanceData, key)
+	return true
 }
 
 /*
 UpdateStatus updates the exit status of the Response and adds a statusMessage to the outputMessages that
 will be displayed when the check exits.
-See updateStatusCode(int) for a detailed description of the algorithm that is used to update the status code.
+See updateStatusCode(Status) for a detailed description of the algorithm that is used to update the status code.
 */
-func (r *Response) UpdateStatus(statusCode int, statusMessage string) {
-	r.updateStatusCode(statusCode)
-	if statusMessage != "" {
-		r.outputMessages = append(r.outputMessages, OutputMessage{statusCode, statusMessage})
+func (r *Response) UpdateStatus(statusCode Status, statusMessage string) {
+	defer r.beginModification()()
+	message := OutputMessage{Status: statusCode, Message: statusMessage}
+	if r.checkFinalizedStatusUpdate(message) {
+		r.updateStatus(message)
+	}
+}
+
+// updateStatus updates the exit status of the Response and adds the message if it is not empty.
+func (r *Response) updateStatus(message OutputMessage) {
+	oldStatusCode := r.statusCode
+	r.updateStatusCode(message.Status)
+	if message.Message != "" {
+		r.outputMessages = append(r.outputMessages, message)
+	} else if !r.hasSilentStatusUpdate(message.Status) {
+		r.silentStatusUpdates = append(r.silentStatusUpdates, message.Status)
+	}
+	if r.statusCode != oldStatusCode {
+		r.debug("status changed", "old", oldStatusCode, "new", r.statusCode, "message", message.Message)
+		for _, hook := range r.onStatusChange {
+			hook(oldStatusCode, r.statusCode, message)
+		}
+	}
+}
+
+// hasSilentStatusUpdate checks if there was already a status update without a message with the given status. Only the
+// statuses matter for recalculateStatusCode(), so repeated updates, e.g. by validate(), are not recorded again.
+func (r *Response) hasSilentStatusUpdate(statusCode Status) bool {
+	for _, s := range r.silentStatusUpdates {
+		if s == statusCode {
+			return true
+		}
 	}
+	return false
+}
+
+/*
+OnStatusChange adds a hook that is called whenever the status code of the Response actually changes, e.g. for
+logging state transitions or for collecting extra diagnostics once something goes WARNING or worse.
+The hook receives the old and new status code and the output message that triggered the change.
+Hooks are called in the order they were added.
+Usage:
+	Response.OnStatusChange(func(old, new Status, trigger OutputMessage) {
+		log.Printf("status changed from %s to %s: %s", old, new, trigger.Message)
+	})
+*/
+func (r *Response) OnStatusChange(hook func(old, new Status, trigger OutputMessage)) {
+	r.onStatusChange = append(r.onStatusChange, hook)
 }
 
-// GetStatusCode returns the current status code.
-func (r *Response) GetStatusCode() int {
+// GetStatusCode returns the current status code, without the overrides of SetMaxStatus and ForceStatus.
+func (r *Response) GetStatusCode() Status {
 	return r.statusCode
 }
 
+// Messages returns all output messages with one of the given statuses, or all output messages if no status is given.
+func (r *Response) Messages(statuses ...Status) []OutputMessage {
+	var messages []OutputMessage
+	for _, message := range r.outputMessages {
+		if len(statuses) == 0 || containsStatus(statuses, message.Status) {
+			messages = append(messages, message)
+		}
+	}
+	return messages
+}
+
+func containsStatus(statuses []Status, status Status) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+RemoveMessages removes all output messages for which the predicate returns true, e.g. to drop known noise before the
+output is printed. The status code is recalculated from the remaining messages and the status updates without a
+message. It returns the number of removed messages.
+Usage:
+	Response.RemoveMessages(func(message OutputMessage) bool {
+		return strings.HasPrefix(message.Message, "snapshot volume")
+	})
+*/
+func (r *Response) RemoveMessages(predicate func(OutputMessage) bool) int {
+	var messages []OutputMessage
+	for _, message := range r.outputMessages {
+		if predicate(message) {
+			r.debug("output message removed", "status", message.Status, "message", message.Message)
+		} else {
+			messages = append(messages, message)
+		}
+	}
+	removed := len(r.outputMessages) - len(messages)
+	r.outputMessages = messages
+	r.recalculateStatusCode()
+	return removed
+}
+
+/*
+ReplaceMessage replaces all output messages for which the predicate returns true with the given message.
+The status code is recalculated like in RemoveMessages(func(OutputMessage) bool). It returns the number of replaced
+messages.
+*/
+func (r *Response) ReplaceMessage(predicate func(OutputMessage) bool, message OutputMessage) int {
+	var replaced int
+	for i, m := range r.outputMessages {
+		if predicate(m) {
+			r.outputMessages[i] = message
+			replaced++
+		}
+	}
+	r.recalculateStatusCode()
+	return replaced
+}
+
+// recalculateStatusCode calculates the status code from the output messages and the status updates without a message.
+func (r *Response) recalculateStatusCode() {
+	r.statusCode = OK
+	for _, statusCode := range r.silentStatusUpdates {
+		r.updateStatusCode(statusCode)
+	}
+	for _, message := range r.outputMessages {
+		r.updateStatusCode(message.Status)
+	}
+}
+
+// HasStatus checks if there is at least one output message with the given status.
+func (r *Response) HasStatus(status Status) bool {
+	for _, message := range r.outputMessages {
+		if message.Status == status {
+			return true
+		}
+	}
+	return false
+}
+
+// MessageCount returns the number of output messages.
+func (r *Response) MessageCount() int {
+	return len(r.outputMessages)
+}
+
+/*
+WorstMessage returns the most severe output message according to the status hierarchy
+CRITICAL > DEPENDENT > UNKNOWN > WARNING > OK. If there are multiple messages with the same status, the first one is
+returned. If there are no output messages, false is returned.
+*/
+func (r *Response) WorstMessage() (OutputMessage, bool) {
+	if len(r.outputMessages) == 0 {
+		return OutputMessage{}, false
+	}
+	worst := r.outputMessages[0]
+	for _, message := range r.outputMessages[1:] {
+		if isWorseStatus(message.Status, worst.Status) {
+			worst = message
+		}
+	}
+	return worst, true
+}
+
+/*
+SetMaxPrecision sets the maximum number of decimal places of the values, min and max of the printed performance data.
+Values with more decimal places are rounded and trailing zeros are removed. Values are always printed as plain decimal
+numbers without exponent notation, which some performance data parsers mishandle. A precision set with
+PerformanceDataPoint.SetFormat(int) takes precedence. A negative value disables the limit, which is the default.
+Example:
+	Response.SetMaxPrecision(3)
+	//'load'=0.123 'uptime'=1000000000 'ratio'=0
+*/
+func (r *Response) SetMaxPrecision(precision int) {
+	r.maxPrecision = precision
+	r.hasMaxPrecision = precision >= 0
+}
+
+/*
+SetMetricPrefix sets a prefix that is prepended to the metric of all performance data points that are added
+afterwards, so plugins that merge results from several modules can avoid metric name collisions. The prefix is part of
+the metric, so it is also contained in JSON labels and threshold violation messages.
+Example:
+	Response.SetMetricPrefix("ifstat_")
+	err := Response.AddPerformanceDataPoint(NewPerformanceDataPoint("in_octets", 123))
+	//'ifstat_in_octets'=123
+*/
+func (r *Response) SetMetricPrefix(prefix string) {
+	r.metricPrefix = prefix
+}
+
 // SetPerformanceDataJSONLabel updates the JSON metric.
 func (r *Response) SetPerformanceDataJSONLabel(jsonLabel bool) {
-	r.performanceDataJSONLabel = jsonLabel
+	r.labelEncoding = LabelEncodingSuffix
+	if jsonLabel {
+		r.labelEncoding = LabelEncodingJSON
+	}
 }
 
 // SetInvalidCharacterBehavior sets the desired behavior if an invalid character is found in a message.
@@ -144,22 +638,49 @@ func (r *Response) SetInvalidCharacterBehavior(behavior InvalidCharacterBehavior
 		fallthrough
 	case InvalidCharacterRemove, InvalidCharacterRemoveMessage, InvalidCharacterReplaceWithError, InvalidCharacterReplaceWithErrorAndSetUNKNOWN:
 		r.invalidCharacterBehaviour = behavior
+	case InvalidCharacterCallback:
+		if r.invalidCharacterCallback == nil {
+			return errors.New("no invalid character callback set")
+		}
+		r.invalidCharacterBehaviour = behavior
 	default:
 		return errors.New("unknown behavior")
 	}
 	return nil
 }
 
+/*
+SetInvalidCharacterCallback sets a callback that is used to sanitize messages that contain an invalid character and
+sets the invalid character behavior to InvalidCharacterCallback.
+Usage:
+	err := Response.SetInvalidCharacterCallback(func(message OutputMessage) (OutputMessage, bool) {
+		message.Message = strings.ReplaceAll(message.Message, "|", "¦")
+		return message, true
+	})
+	if err != nil {
+		...
+	}
+*/
+func (r *Response) SetInvalidCharacterCallback(callback InvalidCharacterCallbackFunc) error {
+	if callback == nil {
+		return errors.New("invalid character callback is nil")
+	}
+	r.invalidCharacterCallback = callback
+	r.invalidCharacterBehaviour = InvalidCharacterCallback
+	return nil
+}
+
 /*
 This function updates the statusCode of the Response. The status code is mapped to a state like this:
 0 = OK
 1 = WARNING
 2 = CRITICAL
 3 = UNKNOWN
+4 = DEPENDENT (only if activated with AllowDependentStatus(bool))
 Everything else is also mapped to UNKNOWN.
 
 UpdateStatus uses the following algorithm to update the exit status:
-CRITICAL > UNKNOWN > WARNING > OK
+CRITICAL > DEPENDENT > UNKNOWN > WARNING > OK
 Everything "left" from the current status code is seen as worse than the current one.
 If the function wants to set a status code, it will only update it if the new status code is "left" of the current one.
 Example:
@@ -171,7 +692,7 @@ Example:
 	Response.updateStatusCode(3) //nothing changes, because CRITICAL is worse than UNKNOWN
 
 */
-func (r *Response) updateStatusCode(statusCode int) {
+func (r *Response) updateStatusCode(statusCode Status) {
 	if r.statusCode == CRITICAL { //critical is the worst status code; if its critical, do not change anything
 		return
 	}
@@ -179,7 +700,7 @@ func (r *Response) updateStatusCode(statusCode int) {
 		r.statusCode = statusCode
 		return
 	}
-	if statusCode < OK || statusCode > UNKNOWN {
+	if (statusCode < OK || statusCode > UNKNOWN) && !(statusCode == DEPENDENT && r.dependentStatus) {
 		statusCode = UNKNOWN
 	}
 	if statusCode > r.statusCode {
@@ -187,8 +708,75 @@ func (r *Response) updateStatusCode(statusCode int) {
 	}
 }
 
+// isWorseStatus checks if status a is worse than status b according to the status hierarchy (see updateStatusCode(Status)).
+func isWorseStatus(a, b Status) bool {
+	return statusSeverity(a) > statusSeverity(b)
+}
+
+// statusSeverity maps a status code to its position in the status hierarchy
+// CRITICAL > DEPENDENT > UNKNOWN > WARNING > OK.
+func statusSeverity(statusCode Status) int {
+	switch statusCode {
+	case OK:
+		return 0
+	case WARNING:
+		return 1
+	case DEPENDENT:
+		return 3
+	case CRITICAL:
+		return 4
+	default:
+		return 2
+	}
+}
+
+/*
+AllowDependentStatus activates or deactivates the DEPENDENT status. If activated, DEPENDENT is not mapped to UNKNOWN,
+so wrapper check plugins that proxy other checks can propagate the dependent state.
+*/
+func (r *Response) AllowDependentStatus(b bool) {
+	r.dependentStatus = b
+}
+
+/*
+SetMaxStatus caps the status of the check plugin at the given status, e.g. at WARNING during a known maintenance.
+The computed status is still available in the ResponseInfo.
+*/
+func (r *Response) SetMaxStatus(statusCode Status) {
+	r.maxStatus = statusCode
+	r.hasMaxStatus = true
+}
+
+/*
+ForceStatus overrides the computed status of the check plugin with the given status at output time.
+The computed status is still available in the ResponseInfo for auditing.
+Invalid status codes are mapped to UNKNOWN.
+*/
+func (r *Response) ForceStatus(statusCode Status) {
+	if statusCode < OK || statusCode > UNKNOWN {
+		statusCode = UNKNOWN
+	}
+	r.forcedStatus = statusCode
+	r.statusForced = true
+}
+
+// outputStatus returns the status that is printed and used as exit code, after applying the status overrides.
+func (r *Response) outputStatus() Status {
+	if r.statusForced {
+		return r.forcedStatus
+	}
+	status := r.statusCode
+	if r.hasMaxStatus && isWorseStatus(status, r.maxStatus) {
+		status = r.maxStatus
+	}
+	if r.maintenanceMode && isWorseStatus(status, r.maintenanceMaxStatus) {
+		status = r.maintenanceMaxStatus
+	}
+	return status
+}
+
 // UpdateStatusIf calls UpdateStatus(statusCode, statusMessage) if the given condition is true.
-func (r *Response) UpdateStatusIf(condition bool, statusCode int, statusMessage string) bool {
+func (r *Response) UpdateStatusIf(condition bool, statusCode Status, statusMessage string) bool {
 	if condition {
 		r.UpdateStatus(statusCode, statusMessage)
 	}
@@ -196,23 +784,50 @@ func (r *Response) UpdateStatusIf(condition bool, statusCode int, statusMessage
 }
 
 // UpdateStatusIfNot calls UpdateStatus(statusCode, statusMessage) if the given condition is false.
-func (r *Response) UpdateStatusIfNot(condition bool, statusCode int, statusMessage string) bool {
+func (r *Response) UpdateStatusIfNot(condition bool, statusCode Status, statusMessage string) bool {
 	if !condition {
 		r.UpdateStatus(statusCode, statusMessage)
 	}
 	return !condition
 }
 
+// UpdateStatusIfAll calls UpdateStatus(statusCode, statusMessage) if all given conditions are true.
+// Without conditions, the status is not updated.
+func (r *Response) UpdateStatusIfAll(conditions []bool, statusCode Status, statusMessage string) bool {
+	if len(conditions) == 0 {
+		return false
+	}
+	for _, condition := range conditions {
+		if !condition {
+			return false
+		}
+	}
+	r.UpdateStatus(statusCode, statusMessage)
+	return true
+}
+
+// UpdateStatusIfAny calls UpdateStatus(statusCode, statusMessage) if at least one of the given conditions is true.
+func (r *Response) UpdateStatusIfAny(conditions []bool, statusCode Status, statusMessage string) bool {
+	for _, condition := range conditions {
+		if condition {
+			r.UpdateStatus(statusCode, statusMessage)
+			return true
+		}
+	}
+	return false
+}
+
 // UpdateStatusOnError calls UpdateStatus(statusCode, statusMessage) if the given error is not nil.
-func (r *Response) UpdateStatusOnError(err error, statusCode int, statusMessage string, includeErrorMessage bool) bool {
+// See SetErrorChainDepth(int) and IncludeErrorType(bool) for how the error message is rendered.
+func (r *Response) UpdateStatusOnError(err error, statusCode Status, statusMessage string, includeErrorMessage bool) bool {
 	x := err != nil
 	if x {
 		msg := statusMessage
 		if includeErrorMessage {
 			if msg != "" {
-				msg = fmt.Sprintf("%s (error: %s)", msg, err)
+				msg = fmt.Sprintf("%s (error: %s)", msg, r.errorText(err))
 			} else {
-				msg = err.Error()
+				msg = r.errorText(err)
 			}
 		}
 		r.UpdateStatus(statusCode, msg)
@@ -220,6 +835,65 @@ func (r *Response) UpdateStatusOnError(err error, statusCode int, statusMessage
 	return x
 }
 
+/*
+UpdateStatusOnErrors calls UpdateStatus(statusCode, message) once for every error that is not nil.
+Errors that wrap multiple errors (e.g. created by errors.Join) are split up, so one message is added per underlying
+error. If prefix is not empty, the messages have the format "prefix (error: err)".
+It returns true if at least one error was not nil.
+*/
+func (r *Response) UpdateStatusOnErrors(statusCode Status, prefix string, errs ...error) bool {
+	found := false
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			if r.UpdateStatusOnErrors(statusCode, prefix, joined.Unwrap()...) {
+				found = true
+			}
+			continue
+		}
+		r.UpdateStatusOnError(err, statusCode, prefix, true)
+		found = true
+	}
+	return found
+}
+
+/*
+UpdateStatusIfAbove updates the status to WARNING or CRITICAL if the value is above warn or crit.
+If addPerformanceData is true, a PerformanceDataPoint with the given name as metric and matching thresholds is added
+to the response as well.
+Usage:
+	err := Response.UpdateStatusIfAbove(cpuLoad, 80, 90, "cpu_load", true)
+	if err != nil {
+		...
+	}
+*/
+func (r *Response) UpdateStatusIfAbove(value, warn, crit float64, name string, addPerformanceData bool) error {
+	return r.updateStatusByThresholds(value, NewThresholds(nil, warn, nil, crit), name, addPerformanceData)
+}
+
+/*
+UpdateStatusIfBelow updates the status to WARNING or CRITICAL if the value is below warn or crit.
+If addPerformanceData is true, a PerformanceDataPoint with the given name as metric and matching thresholds is added
+to the response as well.
+Usage:
+	err := Response.UpdateStatusIfBelow(freeDiskSpace, 20, 10, "free_disk_space", true)
+	if err != nil {
+		...
+	}
+*/
+func (r *Response) UpdateStatusIfBelow(value, warn, crit float64, name string, addPerformanceData bool) error {
+	return r.updateStatusByThresholds(value, NewThresholds(warn, nil, crit, nil), name, addPerformanceData)
+}
+
+func (r *Response) updateStatusByThresholds(value float64, thresholds Thresholds, name string, addPerformanceData bool) error {
+	if addPerformanceData {
+		return r.AddPerformanceDataPoint(NewPerformanceDataPoint(name, value).SetThresholds(thresholds))
+	}
+	return r.CheckThresholds(thresholds, value, name)
+}
+
 /*
 SetOutputDelimiter is used to set the delimiter that is used to separate the outputMessages that will be displayed when
 the check plugin exits. The default value is a linebreak (\n)
@@ -238,11 +912,240 @@ func (r *Response) OutputDelimiterMultiline() {
 	r.SetOutputDelimiter("\n")
 }
 
+// AutoCheckThresholds activates or deactivates checking the values of added performance data points against their
+// thresholds (see AddPerformanceDataPoint(*PerformanceDataPoint)). It is activated by default.
+func (r *Response) AutoCheckThresholds(b bool) {
+	r.autoCheckThresholds = b
+}
+
+// InferMinMaxFromThresholds activates or deactivates inferring min and max from the thresholds for all performance
+// data points that are added (see PerformanceDataPoint.InferMinMaxFromThresholds()).
+func (r *Response) InferMinMaxFromThresholds(b bool) {
+	r.inferMinMax = b
+}
+
 // PrintPerformanceData activates or deactivates printing performance data
 func (r *Response) PrintPerformanceData(b bool) {
 	r.printPerformanceData = b
 }
 
+/*
+AutoOkMessage activates or deactivates the automatically composed default OK message.
+If activated and the check exits with status OK, the default OK message is replaced with a summary of the collected
+performance data, e.g. "12 metrics collected, all within thresholds (worst: memory_usage 78%)".
+If no performance data was collected, the default OK message passed to NewResponse is used.
+*/
+func (r *Response) AutoOkMessage(b bool) {
+	r.autoOkMessage = b
+}
+
+// SetDefaultOkMessage sets the default OK message (see NewResponse(string, ...Option)).
+func (r *Response) SetDefaultOkMessage(defaultOkMessage string) {
+	r.defaultOkMessage = defaultOkMessage
+	r.initialDefaultOkMessage = defaultOkMessage
+	r.defaultOkMessageFunc = nil
+}
+
+/*
+SetDefaultOkMessagef sets the default OK message according to a format specifier, so it can include values that were
+computed during the check.
+Usage:
+	Response.SetDefaultOkMessagef("%d disks checked", len(disks))
+*/
+func (r *Response) SetDefaultOkMessagef(format string, a ...interface{}) {
+	r.SetDefaultOkMessage(fmt.Sprintf(format, a...))
+}
+
+/*
+SetDefaultOkMessageFunc sets a function that returns the default OK message. The function is evaluated lazily when
+the output is generated, so the default OK message can include values like counts or versions that are only known
+at the end of the check.
+Usage:
+	var checked int
+	Response.SetDefaultOkMessageFunc(func() string {
+		return fmt.Sprintf("%d disks checked", checked)
+	})
+*/
+func (r *Response) SetDefaultOkMessageFunc(fn func() string) {
+	r.defaultOkMessageFunc = fn
+}
+
+// HideDefaultOkMessage activates or deactivates omitting the default OK message entirely.
+func (r *Response) HideDefaultOkMessage(b bool) {
+	r.hideDefaultOkMessage = b
+}
+
+/*
+HideRedundantDefaultOkMessage activates or deactivates omitting the default OK message if there are other output
+messages with status OK, which usually already say what was checked.
+*/
+func (r *Response) HideRedundantDefaultOkMessage(b bool) {
+	r.hideRedundantOkMessage = b
+}
+
+/*
+PrefixMessagesWithStatus activates or deactivates prefixing every output message with its status, which makes outputs
+with many messages of mixed severities easier to read.
+Example:
+	[WARNING] disk /var is 85% full
+*/
+func (r *Response) PrefixMessagesWithStatus(b bool) {
+	r.prefixMessagesWithStatus = b
+}
+
+// messageText returns the text of an output message as it is printed.
+func (r *Response) messageText(message OutputMessage) string {
+	text := message.text()
+	if r.prefixMessagesWithStatus {
+		text = "[" + r.statusText(message.Status) + "] " + text
+	}
+	return r.indentLines(text, r.indent)
+}
+
+// showDefaultOkMessage checks if the default OK message is part of the output.
+func (r *Response) showDefaultOkMessage() bool {
+	if r.statusCode != OK || r.hideDefaultOkMessage {
+		return false
+	}
+	return !r.hideRedundantOkMessage || !r.HasStatus(OK)
+}
+
+/*
+SetMessageDeduplication activates or deactivates the deduplication of output messages. If activated, output messages
+with the same status and text are collapsed into one message with a repeat counter, e.g. "interface is down (x42)".
+The status code is not affected.
+*/
+func (r *Response) SetMessageDeduplication(b bool) {
+	r.messageDeduplication = b
+}
+
+/*
+SetMaxMessages sets the maximum number of output messages. If there are more output messages, only the most severe
+ones are kept and a line with the number of omitted messages is appended, which protects dashboards from huge outputs
+on mass failures. A value of 0 or less disables the limit, which is the default.
+Example:
+	Response.SetMaxMessages(10)
+	//... and 57 more (12 critical, 45 warning)
+*/
+func (r *Response) SetMaxMessages(n int) {
+	r.maxMessages = n
+}
+
+/*
+SetMaxMessageLength sets the maximum length (in characters) of a single output message.
+Longer messages are truncated and end with an ellipsis ("...") when the response is validated.
+A value of 0 or less disables truncation, which is the default.
+*/
+func (r *Response) SetMaxMessageLength(n int) {
+	r.maxMessageLength = n
+}
+
+/*
+SetQuiet activates or deactivates the quiet mode. In quiet mode only the first line of the output is printed together
+with the performance data ("STATUS: summary | perfdata"). If no summary is set and the status is OK, the first line
+contains the default OK message, otherwise the first line of the first (most severe, if sorted by status) output
+message. Sub-checks, info messages and long output are suppressed. All output messages are still contained in the
+ResponseInfo.
+*/
+func (r *Response) SetQuiet(quiet bool) {
+	r.quiet = quiet
+}
+
+/*
+SetMaxLineWidth sets the maximum width (in characters) of the output lines. Longer lines are wrapped at word
+boundaries. The first line of the output and the performance data are never wrapped. Words that are longer than the
+maximum width are not split.
+A value of 0 or less disables wrapping, which is the default.
+*/
+func (r *Response) SetMaxLineWidth(n int) {
+	r.maxLineWidth = n
+}
+
+/*
+SetIndentation sets the prefix that is used to indent sub-check sections per level and, if activated with
+IndentContinuationLines(bool), continuation lines of multi-line output messages and long output. The default is four
+spaces.
+Example:
+	Response.SetIndentation("  ")
+	//\_ [WARNING] disk1
+	//  disk is almost full
+*/
+func (r *Response) SetIndentation(prefix string) {
+	r.indent = prefix
+}
+
+/*
+IndentContinuationLines activates or deactivates indenting all lines but the first one of multi-line output messages
+and long output with the indentation prefix (see SetIndentation(string)), so multi-level results render as a
+readable tree.
+*/
+func (r *Response) IndentContinuationLines(b bool) {
+	r.indentContinuationLines = b
+}
+
+// indentLines indents all lines but the first one with the given prefix, if continuation lines are indented.
+func (r *Response) indentLines(text, prefix string) string {
+	if !r.indentContinuationLines {
+		return text
+	}
+	return strings.ReplaceAll(text, "\n", "\n"+prefix)
+}
+
+/*
+SetSummary sets a short summary that is displayed on the first line of the output, followed by the performance data.
+All output messages and the long output are displayed in the following lines, as described in the Monitoring Plugins
+Development Guidelines. If a summary is set, the default OK message is not displayed.
+Example:
+	Response.SetSummary("3 of 5 disks are critical")
+	//this results in the output having the following format:
+	//CRITICAL: 3 of 5 disks are critical | performanceData
+	//outputMessage1
+	//outputMessage2
+*/
+func (r *Response) SetSummary(summary string) {
+	defer r.beginModification()()
+	if r.handlingSignal() {
+		return
+	}
+	r.summary = summary
+}
+
+/*
+EnableStatusSummary enables the status summary. The number of output messages per status (e.g.
+"3 critical, 2 warning, 10 ok") is displayed as summary on the first line of the output (see SetSummary(string)).
+If a summary is set, the status counts are prepended to it.
+*/
+func (r *Response) EnableStatusSummary() {
+	r.statusSummary = true
+}
+
+// AddLongOutput adds a text to the long output, which is displayed after the output messages.
+func (r *Response) AddLongOutput(text string) {
+	defer r.beginModification()()
+	if r.handlingSignal() {
+		return
+	}
+	r.longOutput = append(r.longOutput, text)
+}
+
+/*
+AddInfoMessage adds a purely informational message that never affects the status. Info messages are displayed after
+the output messages, but only if the verbose mode is activated or the status is not OK, which keeps routine OK
+outputs short.
+*/
+func (r *Response) AddInfoMessage(msg string) {
+	defer r.beginModification()()
+	if r.handlingSignal() {
+		return
+	}
+	r.infoMessages = append(r.infoMessages, msg)
+}
+
+// SetVerbose activates or deactivates the verbose mode. If activated, info messages are always displayed.
+func (r *Response) SetVerbose(verbose bool) {
+	r.verbose = verbose
+}
+
 // SortOutputMessagesByStatus sorts the output messages according to their status.
 func (r *Response) SortOutputMessagesByStatus(b bool) {
 	r.sortOutputMessagesByStatus = b
@@ -253,42 +1156,273 @@ func (r *Response) outputString() string {
 	return string(r.output())
 }
 
+// String validates the response and returns the output that will be returned by the check plugin as a string.
+func (r *Response) String() string {
+	return string(r.Bytes())
+}
+
+// Bytes validates the response and returns the output that will be returned by the check plugin.
+func (r *Response) Bytes() []byte {
+	r.validate()
+	return r.output()
+}
+
+/*
+SetOutputTemplate sets a template that is used to render the output. The template receives the ResponseInfo, whose
+RawOutput contains the output in the default format. If the template can not be executed, the output in the default
+format is used.
+Example:
+	tmpl := template.Must(template.New("output").Parse("[{{.StatusCode}}] {{len .Messages}} findings"))
+	Response.SetOutputTemplate(tmpl)
+*/
+func (r *Response) SetOutputTemplate(tmpl *template.Template) {
+	r.outputTemplate = tmpl
+}
+
+/*
+SetLogger sets a logger that receives structured debug events, e.g. status transitions, threshold violations, dropped
+messages and invalid characters. The events are logged with level debug, modifications that are ignored because the
+Response was finalized (see Finalize()) with level warn. The logger should not write to stdout, because stdout is
+parsed by the monitoring system. log/slog requires Go 1.21, which is therefore the minimum Go version of the package.
+Example:
+	Response.SetLogger(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug})))
+*/
+func (r *Response) SetLogger(logger *slog.Logger) {
+	r.logger = logger
+}
+
+// debug logs a debug event if a logger is set.
+func (r *Response) debug(msg string, args ...interface{}) {
+	if r.logger != nil {
+		r.logger.Debug(msg, args...)
+	}
+}
+
+// warn logs a warning if a logger is set.
+func (r *Response) warn(msg string, args ...interface{}) {
+	if r.logger != nil {
+		r.logger.Warn(msg, args...)
+	}
+}
+
+/*
+SetIgnoreSIGPIPE activates or deactivates ignoring SIGPIPE in OutputAndExit(). If it is activated and stdout was
+already closed by the consumer (e.g. after a timeout), the output is discarded instead of the check plugin being
+killed by SIGPIPE, so it still exits with the current exit code. SIGPIPE is ignored process-wide, so it is deactivated
+by default and only ignored if the output is printed to stdout (see SetOutputWriter(io.Writer)).
+*/
+func (r *Response) SetIgnoreSIGPIPE(b bool) {
+	r.ignoreSIGPIPE = b
+}
+
+/*
+SetDryRun activates or deactivates the dry-run mode. In dry-run mode OutputAndExit() prints the output, but neither
+calls the exit hooks and sinks nor exits, so scripts can preview exactly what would be printed without side effects.
+*/
+func (r *Response) SetDryRun(b bool) {
+	r.dryRun = b
+}
+
 // This function returns the output that will be returned by the check plugin.
 func (r *Response) output() []byte {
+	output := r.unstreamedOutput()
+	if r.performanceDataStream != nil {
+		buffer := bytes.NewBuffer(output)
+		_ = r.writeStreamedPerformanceData(buffer, output)
+		output = buffer.Bytes()
+	}
+	return output
+}
+
+// This function returns the output without the streamed performance data (see EnablePerformanceDataStreaming).
+func (r *Response) unstreamedOutput() []byte {
+	output := r.renderOutput()
+	if r.forceASCII {
+		return toASCII(output)
+	}
+	return output
+}
+
+// This function renders the output. If an output template is set, the output is rendered with it.
+func (r *Response) renderOutput() []byte {
+	output := r.defaultOutput()
+	if r.outputTemplate == nil {
+		return output
+	}
+	var buffer bytes.Buffer
+	err := r.outputTemplate.Execute(&buffer, ResponseInfo{
+		StatusCode:         r.outputStatus(),
+		ComputedStatusCode: r.statusCode,
+		PerformanceData:    r.sortPerformanceData(r.performanceData.getInfo()),
+		RawOutput:          string(output),
+		Runtime:            time.Since(r.startTime),
+		Messages:           r.outputMessages,
+		Check:              r.checkMetadata,
+		Violations:         r.violations,
+	})
+	if err != nil {
+		return output
+	}
+	return buffer.Bytes()
+}
+
+// This function returns the output in the default format.
+func (r *Response) defaultOutput() []byte {
+	summary := r.summaryLine()
 	var buffer bytes.Buffer
-	buffer.WriteString(StatusCode2Text(r.statusCode))
+	buffer.WriteString(r.statusText(r.outputStatus()))
 	buffer.WriteString(": ")
-	if r.statusCode == OK {
-		buffer.WriteString(r.defaultOkMessage)
-		if len(r.outputMessages) > 0 {
-			buffer.WriteString(r.outputDelimiter)
+	switch {
+	case r.quiet:
+		buffer.WriteString(strings.SplitN(r.firstLine(), "\n", 2)[0])
+	case summary != "":
+		buffer.WriteString(summary)
+		if len(r.messageLines()) > 0 {
+			buffer.WriteByte('\n')
+		}
+		r.writeOutputMessages(&buffer)
+	default:
+		if r.showDefaultOkMessage() {
+			buffer.WriteString(r.defaultOkMessage)
+			if len(r.messageLines()) > 0 {
+				buffer.WriteString(r.outputDelimiter)
+			}
 		}
+		r.writeOutputMessages(&buffer)
 	}
 
-	for c, x := range r.outputMessages {
-		if c != 0 {
-			buffer.WriteString(r.outputDelimiter)
+	if !r.quiet {
+		r.writeSubChecks(&buffer, r.subChecks, 0)
+		for _, text := range r.longOutput {
+			buffer.WriteByte('\n')
+			buffer.WriteString(r.indentLines(text, r.indent))
+		}
+		if r.printCheckMetadata && r.checkMetadata != nil {
+			buffer.WriteByte('\n')
+			buffer.WriteString(r.removeInvalidCharacters(r.checkMetadata.String()))
 		}
-		buffer.WriteString(x.Message)
 	}
 
-	if r.printPerformanceData {
-		firstPoint := true
-		for _, perfDataPoint := range r.performanceData {
-			if firstPoint {
-				buffer.WriteString(" | ")
-				firstPoint = false
-			} else {
-				buffer.WriteByte(' ')
-			}
-			buffer.Write(perfDataPoint.output(r.performanceDataJSONLabel))
+	text := buffer.String()
+	// if there is no message, e.g. because the status results from sub-checks, only the status is printed
+	if status := r.statusText(r.outputStatus()); text == status+": " || strings.HasPrefix(text, status+": \n") {
+		text = status + text[len(status)+2:]
+	}
+	if r.maxLineWidth > 0 {
+		text = wrapLines(text, r.maxLineWidth)
+	}
+
+	performanceData := r.performanceDataOutput()
+	if performanceData == "" {
+		return []byte(text)
+	}
+	// with a summary, the performance data follows the summary line as described in the guidelines
+	if summary != "" {
+		lines := strings.SplitN(text, "\n", 2)
+		lines[0] += " | " + performanceData
+		return []byte(strings.Join(lines, "\n"))
+	}
+	return []byte(text + " | " + performanceData)
+}
+
+// summaryLine returns the summary, prepended by the status counts if the status summary is enabled.
+func (r *Response) summaryLine() string {
+	summary := r.summary
+	if summary == "" && r.summaryStrategy != nil {
+		summary = r.summaryStrategy(r)
+	}
+	if !r.statusSummary {
+		return summary
+	}
+	counts := r.statusCounts()
+	switch {
+	case counts == "":
+		return summary
+	case summary == "":
+		return counts
+	default:
+		return counts + " - " + summary
+	}
+}
+
+// statusCounts returns the number of output messages per status, e.g. "3 critical, 2 warning, 10 ok".
+func (r *Response) statusCounts() string {
+	var counts []string
+	for _, status := range statusesBySeverity {
+		if n := len(r.Messages(status)); n > 0 {
+			counts = append(counts, strconv.Itoa(n)+" "+strings.ToLower(r.statusText(status)))
 		}
 	}
-	return buffer.Bytes()
+	return strings.Join(counts, ", ")
+}
+
+// firstLine returns the text of the first line of the output (without the status).
+func (r *Response) firstLine() string {
+	switch {
+	case r.summaryLine() != "":
+		return r.summaryLine()
+	case r.showDefaultOkMessage():
+		return r.defaultOkMessage
+	case len(r.outputMessages) > 0:
+		return r.messageText(r.outputMessages[0])
+	default:
+		return ""
+	}
+}
+
+// messageLines returns the texts of all output messages followed by the info messages, if they are displayed.
+func (r *Response) messageLines() []string {
+	var lines []string
+	for _, message := range r.outputMessages {
+		if text := r.messageText(message); text != "" {
+			lines = append(lines, text)
+		}
+	}
+	if overflow := r.messageOverflow(); overflow != "" {
+		lines = append(lines, overflow)
+	}
+	if overflow := r.performanceDataOverflow(); overflow != "" {
+		lines = append(lines, overflow)
+	}
+	if r.verbose || r.outputStatus() != OK {
+		lines = append(lines, r.infoMessages...)
+	}
+	return lines
+}
+
+func (r *Response) writeOutputMessages(buffer *bytes.Buffer) {
+	buffer.WriteString(strings.Join(r.messageLines(), r.outputDelimiter))
+}
+
+// performanceDataOutput returns all performance data points separated by spaces, if printing is activated.
+func (r *Response) performanceDataOutput() string {
+	if !r.printPerformanceData {
+		return ""
+	}
+	var points []string
+	perfData, _ := r.printedPerformanceData()
+	for _, perfDataPoint := range perfData {
+		points = append(points, string(r.pointOutput(&perfDataPoint)))
+	}
+	return strings.Join(points, " ")
 }
 
 func (r *Response) validate() {
+	// a finalized Response was computed a last time by Finalize()
+	if !r.IsFinalized() {
+		r.computeDerivedPerformanceData()
+		r.aggregateSubChecks()
+	}
+	if r.defaultOkMessageFunc != nil {
+		r.defaultOkMessage = r.defaultOkMessageFunc()
+	}
+	if r.autoOkMessage && r.statusCode == OK {
+		if summary := r.performanceData.summary(); summary != "" {
+			r.defaultOkMessage = summary
+		}
+	}
 	if strings.Contains(r.defaultOkMessage, "|") {
+		r.debug("invalid character in default OK message", "message", r.defaultOkMessage)
 		switch r.invalidCharacterBehaviour {
 		case InvalidCharacterReplace:
 			r.defaultOkMessage = strings.ReplaceAll(r.defaultOkMessage, "|", r.invalidCharacterReplaceChar)
@@ -304,11 +1438,46 @@ func (r *Response) validate() {
 			}}
 			r.outputMessages = nil
 			return
+		case InvalidCharacterCallback:
+			message, ok := r.invalidCharacterCallback(OutputMessage{
+				Status:  OK,
+				Message: r.defaultOkMessage,
+			})
+			if ok {
+				r.defaultOkMessage = strings.ReplaceAll(message.Message, "|", "")
+			} else {
+				r.defaultOkMessage = ""
+			}
 		default: // InvalidCharacterRemove
 			r.defaultOkMessage = strings.ReplaceAll(r.defaultOkMessage, "|", "")
 		}
 	}
 	r.validateMessages()
+	if r.messageDeduplication {
+		r.deduplicateMessages()
+	}
+	r.validateLongOutput()
+	var infoMessages []string
+	for _, msg := range r.infoMessages {
+		if msg = r.removeInvalidCharacters(msg); msg != "" {
+			infoMessages = append(infoMessages, msg)
+		}
+	}
+	r.infoMessages = infoMessages
+	r.validateSubChecks(r.subChecks)
+	r.applyMaintenanceMode()
+	if r.maxMessages > 0 {
+		r.limitMessages()
+	}
+	if r.maxMessageLength > 0 {
+		r.defaultOkMessage = truncateMessage(r.defaultOkMessage, r.maxMessageLength)
+		for i, message := range r.outputMessages {
+			r.outputMessages[i].Message = truncateMessage(message.Message, r.maxMessageLength)
+			if r.outputMessages[i].Message != message.Message {
+				r.debug("output message truncated", "status", message.Status, "message", message.Message)
+			}
+		}
+	}
 	if r.sortOutputMessagesByStatus {
 		r.sortMessagesByStatus()
 	}
@@ -321,34 +1490,40 @@ out:
 		if !strings.Contains(message.Message, "|") {
 			messages = append(messages, message)
 		} else {
+			r.debug("invalid character in output message", "status", message.Status, "message", message.Message)
 			switch r.invalidCharacterBehaviour {
 			case InvalidCharacterReplace:
 				newMessage := strings.ReplaceAll(message.Message, "|", r.invalidCharacterReplaceChar)
 				if newMessage != "" {
-					messages = append(messages, OutputMessage{
-						Status:  message.Status,
-						Message: newMessage,
-					})
+					message.Message = newMessage
+					messages = append(messages, message)
 				}
 			case InvalidCharacterRemoveMessage:
-				// done
+				r.debug("output message dropped", "status", message.Status, "message", message.Message)
 			case InvalidCharacterReplaceWithErrorAndSetUNKNOWN:
 				r.statusCode = UNKNOWN
 				message.Status = UNKNOWN
 				fallthrough
 			case InvalidCharacterReplaceWithError:
+				r.debug("output messages replaced with error", "dropped", len(r.outputMessages))
 				messages = []OutputMessage{{
 					Status:  message.Status,
 					Message: "output message contains invalid character",
 				}}
 				break out
+			case InvalidCharacterCallback:
+				newMessage, ok := r.invalidCharacterCallback(message)
+				newMessage.Message = strings.ReplaceAll(newMessage.Message, "|", "")
+				if ok && newMessage.Message != "" {
+					messages = append(messages, newMessage)
+				} else {
+					r.debug("output message dropped", "status", message.Status, "message", message.Message)
+				}
 			default: // InvalidCharacterRemove
 				newMessage := strings.ReplaceAll(message.Message, "|", "")
 				if newMessage != "" {
-					messages = append(messages, OutputMessage{
-						Status:  message.Status,
-						Message: newMessage,
-					})
+					message.Message = newMessage
+					messages = append(messages, message)
 				}
 			}
 		}
@@ -356,6 +1531,115 @@ out:
 	r.outputMessages = messages
 }
 
+// wrapLines wraps all lines of the text except the first one at word boundaries to the given width.
+func wrapLines(text string, width int) string {
+	lines := strings.Split(text, "\n")
+	var res []string
+	for i, line := range lines {
+		if i == 0 || utf8.RuneCountInString(line) <= width {
+			res = append(res, line)
+			continue
+		}
+		var current string
+		for _, word := range strings.Split(line, " ") {
+			switch {
+			case current == "":
+				current = word
+			case utf8.RuneCountInString(current)+1+utf8.RuneCountInString(word) <= width:
+				current += " " + word
+			default:
+				res = append(res, current)
+				current = word
+			}
+		}
+		res = append(res, current)
+	}
+	return strings.Join(res, "\n")
+}
+
+// deduplicateMessages collapses output messages with the same status and text into one message with a repeat counter.
+func (r *Response) deduplicateMessages() {
+	type messageKey struct {
+		status Status
+		text   string
+	}
+	var messages []OutputMessage
+	counts := make(map[messageKey]int)
+	for _, message := range r.outputMessages {
+		key := messageKey{message.Status, message.text()}
+		if counts[key] == 0 {
+			messages = append(messages, message)
+		}
+		counts[key]++
+	}
+	for i, message := range messages {
+		if n := counts[messageKey{message.Status, message.text()}]; n > 1 {
+			messages[i].Message = message.Message + " (x" + strconv.Itoa(n) + ")"
+		}
+	}
+	r.outputMessages = messages
+}
+
+// limitMessages keeps the maxMessages most severe output messages in their original order and counts the others.
+func (r *Response) limitMessages() {
+	if len(r.outputMessages) <= r.maxMessages {
+		return
+	}
+	indices := make([]int, len(r.outputMessages))
+	for i := range indices {
+		indices[i] = i
+	}
+	sort.SliceStable(indices, func(i, j int) bool {
+		return isWorseStatus(r.outputMessages[indices[i]].Status, r.outputMessages[indices[j]].Status)
+	})
+	keep := make(map[int]bool, r.maxMessages)
+	for _, i := range indices[:r.maxMessages] {
+		keep[i] = true
+	}
+	if r.droppedMessages == nil {
+		r.droppedMessages = make(map[Status]int)
+	}
+	var messages []OutputMessage
+	for i, message := range r.outputMessages {
+		if keep[i] {
+			messages = append(messages, message)
+		} else {
+			r.droppedMessages[message.Status]++
+		}
+	}
+	r.debug("output messages dropped", "dropped", len(r.outputMessages)-len(messages))
+	r.outputMessages = messages
+}
+
+// messageOverflow returns the line that indicates omitted output messages, e.g. "... and 57 more (57 warning)".
+func (r *Response) messageOverflow() string {
+	var total int
+	var counts []string
+	for _, status := range statusesBySeverity {
+		if n := r.droppedMessages[status]; n > 0 {
+			total += n
+			counts = append(counts, strconv.Itoa(n)+" "+strings.ToLower(r.statusText(status)))
+		}
+	}
+	if total == 0 {
+		return ""
+	}
+	return "... and " + strconv.Itoa(total) + " more (" + strings.Join(counts, ", ") + ")"
+}
+
+// truncateMessage truncates the message to maxLength characters including a trailing ellipsis.
+func truncateMessage(message string, maxLength int) string {
+	const ellipsis = "..."
+	runes := []rune(message)
+	if len(runes) <= maxLength {
+		return message
+	}
+	if maxLength <= len(ellipsis) {
+		return string(runes[:maxLength])
+	}
+	return string(runes[:maxLength-len(ellipsis)]) + ellipsis
+}
+
 func (r *Response) sortMessagesByStatus() {
 	sort.Slice(r.outputMessages, func(i, j int) bool {
 		if r.outputMessages[i].Status == CRITICAL {
@@ -366,8 +1650,10 @@ func (r *Response) sortMessagesByStatus() {
 }
 
 /*
-OutputAndExit generates the output string and prints it to stdout.
-After that the check plugin exits with the current exit code.
+OutputAndExit generates the output string and prints it to the output writer (stdout by default).
+After that the check plugin exits with the current exit code. The output is only written once, later calls do nothing
+until the Response is reset, e.g. if the exit function returns and a deferred call follows (see SetExitFunc).
+SIGPIPE is only ignored if it was activated with SetIgnoreSIGPIPE(bool) and the output writer is stdout.
 Example:
 	Response := NewResponse("everything checked!")
 	defer Response.OutputAndExit()
@@ -375,38 +1661,164 @@ Example:
 	//check plugin logic...
 */
 func (r *Response) OutputAndExit() {
+	end := r.beginModification()
+	if r.handlingSignal() {
+		end()
+		// the signal handler writes the output, returning early could end the check plugin before it is written
+		r.waitForSignalHandler()
+		return
+	}
+	defer end()
+	r.outputAndExit()
+}
+
+// outputAndExit works like OutputAndExit(), but does not wait for a signal handler.
+func (r *Response) outputAndExit() {
+	if r.exited {
+		return
+	}
+	r.validate()
+	r.Finalize()
+	if r.dryRun {
+		_, _ = r.outputWriter.Write(append(r.Bytes(), '\n'))
+		return
+	}
+	r.exited = true
+	if len(r.exitHooks) > 0 || len(r.sinks) > 0 {
+		info := r.GetInfo()
+		for _, hook := range r.exitHooks {
+			hook(info)
+		}
+		r.writeSinks(info)
+	}
+	if r.ignoreSIGPIPE && r.outputWriter == os.Stdout {
+		ignoreSIGPIPE()
+	}
+	if r.performanceDataStream != nil {
+		// write errors are ignored, there is no one left to report them to
+		output := r.unstreamedOutput()
+		_, _ = r.outputWriter.Write(output)
+		_ = r.writeStreamedPerformanceData(r.outputWriter, output)
+		_, _ = r.outputWriter.Write([]byte{'\n'})
+		_ = r.Close()
+		r.exitFunc(r.exitCode())
+		return
+	}
+	output, exitCode := r.Output()
+	// write errors are ignored, there is no one left to report them to
+	_, _ = r.outputWriter.Write(output)
+	r.exitFunc(exitCode)
+}
+
+/*
+Output returns the output of the check plugin exactly as it would be printed by OutputAndExit() (including the
+trailing line break) and the exit code, without printing anything or exiting.
+*/
+func (r *Response) Output() ([]byte, int) {
 	r.validate()
-	fmt.Println(r.outputString())
-	os.Exit(r.statusCode)
+	return append(r.output(), '\n'), r.exitCode()
+}
+
+// exitCode returns the exit code of the output status, using the exit code mapping.
+func (r *Response) exitCode() int {
+	status := r.outputStatus()
+	if mapped, ok := r.exitCodeMapping[status]; ok {
+		return mapped
+	}
+	return int(status)
+}
+
+/*
+SetExitCodeMapping sets a mapping from status codes to process exit codes that is used by OutputAndExit(), for
+schedulers that expect other exit codes than 0-3. Status codes that are not contained in the mapping are used as exit
+code directly.
+Example:
+	Response.SetExitCodeMapping(map[Status]int{WARNING: 0, UNKNOWN: 2})
+*/
+func (r *Response) SetExitCodeMapping(mapping map[Status]int) {
+	r.exitCodeMapping = make(map[Status]int, len(mapping))
+	for status, exitCode := range mapping {
+		r.exitCodeMapping[status] = exitCode
+	}
+}
+
+/*
+OnExit adds a hook that is called by OutputAndExit() after the response was validated, but before the output is
+printed and the check plugin exits, e.g. to log results or ship metrics regardless of which code path exits.
+Hooks are called in the order they were added.
+*/
+func (r *Response) OnExit(hook func(info ResponseInfo)) {
+	r.exitHooks = append(r.exitHooks, hook)
+}
+
+/*
+SetExitFunc sets the function that is called by OutputAndExit() with the exit code. The default is os.Exit.
+If the function returns, OutputAndExit() returns as well, which allows using a Response in daemons or tests. The
+Response has to be reset (see Reset()) before OutputAndExit() writes the output again.
+*/
+func (r *Response) SetExitFunc(exitFunc func(int)) {
+	r.exitFunc = exitFunc
+}
+
+// SetOutputWriter sets the writer the output is printed to by OutputAndExit(). The default is stdout.
+func (r *Response) SetOutputWriter(w io.Writer) {
+	r.outputWriter = w
 }
 
 // ResponseInfo has all available information for a response. It also contains the RawOutput.
+// ComputedStatusCode is the status before SetMaxStatus, SetMaintenanceMode and ForceStatus were applied.
 type ResponseInfo struct {
-	StatusCode      int                    `yaml:"status_code" json:"status_code" xml:"status_code"`
-	PerformanceData []PerformanceDataPoint `yaml:"performance_data" json:"performance_data" xml:"performance_data"`
-	RawOutput       string                 `yaml:"raw_output" json:"raw_output" xml:"raw_output"`
-	Messages        []OutputMessage        `yaml:"messages" json:"messages" xml:"messages"`
+	StatusCode         Status                 `yaml:"status_code" json:"status_code" xml:"status_code"`
+	ComputedStatusCode Status                 `yaml:"computed_status_code" json:"computed_status_code" xml:"computed_status_code"`
+	PerformanceData    []PerformanceDataPoint `yaml:"performance_data" json:"performance_data" xml:"performance_data"`
+	RawOutput          string                 `yaml:"raw_output" json:"raw_output" xml:"raw_output"`
+	Runtime            time.Duration          `yaml:"runtime" json:"runtime" xml:"runtime"`
+	Messages           []OutputMessage        `yaml:"messages" json:"messages" xml:"messages"`
+	Check              *CheckMetadata         `yaml:"check,omitempty" json:"check,omitempty" xml:"check,omitempty"`
+	Violations         []ViolationInfo        `yaml:"violations,omitempty" json:"violations,omitempty" xml:"violations,omitempty"`
 }
 
 // GetInfo returns all information for a response.
 func (r *Response) GetInfo() ResponseInfo {
 	r.validate()
 	return ResponseInfo{
-		RawOutput:       r.outputString(),
-		StatusCode:      r.statusCode,
-		PerformanceData: r.performanceData.getInfo(),
-		Messages:        r.outputMessages,
+		RawOutput:          r.outputString(),
+		Runtime:            time.Since(r.startTime),
+		StatusCode:         r.outputStatus(),
+		ComputedStatusCode: r.statusCode,
+		PerformanceData:    r.sortPerformanceData(r.performanceData.getInfo()),
+		Messages:           r.outputMessages,
+		Check:              r.checkMetadata,
+		Violations:         r.violations,
 	}
 }
 
 // CheckThresholds checks if the value exceeds the given thresholds and updates the response
 func (r *Response) CheckThresholds(thresholds Thresholds, value interface{}, name string) error {
-	res, err := thresholds.CheckValue(value)
+	defer r.beginModification()()
+	if err := r.checkFinalized(); err != nil {
+		return errors.Wrap(err, "failed to check thresholds")
+	}
+	return r.checkThresholds(thresholds, value, name, "", nil)
+}
+
+// checkThresholds works like CheckThresholds, but passes the unit to the threshold message template and appends the
+// text returned by detail to the violation message.
+func (r *Response) checkThresholds(thresholds Thresholds, value interface{}, name, unit string,
+	detail func(Status) string) error {
+	violation, err := thresholds.CheckViolation(value)
 	if err != nil {
 		return errors.Wrap(err, "failed to check value against threshold")
 	}
-	if res != OK {
-		r.UpdateStatus(res, name+" is outside of "+StatusCode2Text(res)+" threshold")
+	if violation != nil {
+		violation.Name, violation.Unit, violation.StatusText = name, unit, r.statusText(violation.Status)
+		r.debug("threshold violated", "name", name, "value", value, "status", violation.Status)
+		r.violations = append(r.violations, *violation)
+		message := r.thresholdMessage(*violation)
+		if detail != nil {
+			message += detail(violation.Status)
+		}
+		r.updateStatus(OutputMessage{Status: violation.Status, Message: message})
 	}
 	return nil
 }
@@ -415,7 +1827,7 @@ func (r *Response) CheckThresholds(thresholds Thresholds, value interface{}, nam
 String2StatusCode returns the status code for a string.
 OK -> 1, WARNING -> 2, CRITICAL -> 3, UNKNOWN and everything else -> 4 (case insensitive)
 */
-func String2StatusCode(s string) int {
+func String2StatusCode(s string) Status {
 	switch {
 	case strings.EqualFold("OK", s):
 		return OK
@@ -423,13 +1835,37 @@ func String2StatusCode(s string) int {
 		return WARNING
 	case strings.EqualFold("CRITICAL", s):
 		return CRITICAL
+	case strings.EqualFold("DEPENDENT", s):
+		return DEPENDENT
 	default:
 		return UNKNOWN
 	}
 }
 
+/*
+SetStatusLabels sets custom labels for the status codes, e.g. for localization or for consumers that expect other
+labels like "PASS" and "FAIL". The labels are used as prefix of the output and in threshold violation messages.
+Status codes that are not contained in the map keep their default label (see StatusCode2Text(Status)).
+Example:
+	Response.SetStatusLabels(map[Status]string{WARNING: "WARNUNG", CRITICAL: "KRITISCH", UNKNOWN: "UNBEKANNT"})
+*/
+func (r *Response) SetStatusLabels(labels map[Status]string) {
+	r.statusLabels = make(map[Status]string, len(labels))
+	for status, label := range labels {
+		r.statusLabels[status] = label
+	}
+}
+
+// statusText returns the custom label of the status code if set, otherwise the default label.
+func (r *Response) statusText(statusCode Status) string {
+	if label, ok := r.statusLabels[statusCode]; ok {
+		return label
+	}
+	return StatusCode2Text(statusCode)
+}
+
 // StatusCode2Text is used to map the status code to a string.
-func StatusCode2Text(statusCode int) string {
+func StatusCode2Text(statusCode Status) string {
 	switch {
 	case statusCode == OK:
 		return "OK"
@@ -437,6 +1873,8 @@ func StatusCode2Text(statusCode int) string {
 		return "WARNING"
 	case statusCode == CRITICAL:
 		return "CRITICAL"
+	case statusCode == DEPENDENT:
+		return "DEPENDENT"
 	default:
 		return "UNKNOWN"
 	}
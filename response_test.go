@@ -2,13 +2,16 @@ package monitoringplugin
 
 import (
 	"bytes"
+	stderrors "errors"
 	"github.com/stretchr/testify/assert"
+	"log/slog"
 	"os"
 	"os/exec"
 	"regexp"
 	"strconv"
 	"strings"
 	"testing"
+	"text/template"
 )
 
 func TestOKResponse(t *testing.T) {
@@ -72,7 +75,7 @@ func TestStatusHierarchy(t *testing.T) {
 
 	r.UpdateStatus(OK, "")
 	if r.statusCode != WARNING {
-		t.Error("status code did change from WARNING to " + strconv.Itoa(r.statusCode) + " after UpdateStatus(OK) was called! The function should not affect the status code, because WARNING is worse than OK")
+		t.Error("status code did change from WARNING to " + strconv.Itoa(int(r.statusCode)) + " after UpdateStatus(OK) was called! The function should not affect the status code, because WARNING is worse than OK")
 	}
 
 	r.UpdateStatus(CRITICAL, "")
@@ -82,17 +85,17 @@ func TestStatusHierarchy(t *testing.T) {
 
 	r.UpdateStatus(OK, "")
 	if r.statusCode != CRITICAL {
-		t.Error("status code did change from CRITICAL to " + strconv.Itoa(r.statusCode) + " after UpdateStatus(OK) was called! The function should not affect the status code, because CRITICAL is worse than OK")
+		t.Error("status code did change from CRITICAL to " + strconv.Itoa(int(r.statusCode)) + " after UpdateStatus(OK) was called! The function should not affect the status code, because CRITICAL is worse than OK")
 	}
 
 	r.UpdateStatus(WARNING, "")
 	if r.statusCode != CRITICAL {
-		t.Error("status code did change from CRITICAL to " + strconv.Itoa(r.statusCode) + " after UpdateStatus(WARNING) was called! The function should not affect the status code, because CRITICAL is worse than WARNING")
+		t.Error("status code did change from CRITICAL to " + strconv.Itoa(int(r.statusCode)) + " after UpdateStatus(WARNING) was called! The function should not affect the status code, because CRITICAL is worse than WARNING")
 	}
 
 	r.UpdateStatus(UNKNOWN, "")
 	if r.statusCode != CRITICAL {
-		t.Error("status code did change from CRITICAL to " + strconv.Itoa(r.statusCode) + " after UpdateStatus(UNKNOWN) was called! The function should not affect the status code, because CRITICAL is worse than UNKNOWN")
+		t.Error("status code did change from CRITICAL to " + strconv.Itoa(int(r.statusCode)) + " after UpdateStatus(UNKNOWN) was called! The function should not affect the status code, because CRITICAL is worse than UNKNOWN")
 	}
 
 	r = NewResponse("")
@@ -103,7 +106,7 @@ func TestStatusHierarchy(t *testing.T) {
 
 	r.UpdateStatus(WARNING, "")
 	if r.statusCode != UNKNOWN {
-		t.Error("status code did change from UNKNOWN to " + strconv.Itoa(r.statusCode) + " after UpdateStatus(WARNING) was called! The function should not affect the status code, because UNKNOWN is worse than WARNING")
+		t.Error("status code did change from UNKNOWN to " + strconv.Itoa(int(r.statusCode)) + " after UpdateStatus(WARNING) was called! The function should not affect the status code, because UNKNOWN is worse than WARNING")
 	}
 
 	r.UpdateStatus(CRITICAL, "")
@@ -199,6 +202,26 @@ func TestResponse_UpdateStatusIfNot(t *testing.T) {
 	assert.True(t, r.statusCode == 1)
 }
 
+func TestResponse_UpdateStatusIfAll(t *testing.T) {
+	r := NewResponse("")
+	assert.False(t, r.UpdateStatusIfAll(nil, WARNING, "empty"))
+	assert.False(t, r.UpdateStatusIfAll([]bool{true, false, true}, WARNING, "some"))
+	assert.Equal(t, OK, r.GetStatusCode())
+	assert.True(t, r.UpdateStatusIfAll([]bool{true, true}, WARNING, "all"))
+	assert.Equal(t, WARNING, r.GetStatusCode())
+	assert.Equal(t, []OutputMessage{{Status: WARNING, Message: "all"}}, r.Messages())
+}
+
+func TestResponse_UpdateStatusIfAny(t *testing.T) {
+	r := NewResponse("")
+	assert.False(t, r.UpdateStatusIfAny(nil, CRITICAL, "empty"))
+	assert.False(t, r.UpdateStatusIfAny([]bool{false, false}, CRITICAL, "none"))
+	assert.Equal(t, OK, r.GetStatusCode())
+	assert.True(t, r.UpdateStatusIfAny([]bool{false, true, true}, CRITICAL, "any"))
+	assert.Equal(t, CRITICAL, r.GetStatusCode())
+	assert.Equal(t, []OutputMessage{{Status: CRITICAL, Message: "any"}}, r.Messages())
+}
+
 func TestString2StatusCode(t *testing.T) {
 	assert.True(t, String2StatusCode("ok") == 0)
 	assert.True(t, String2StatusCode("OK") == 0)
@@ -337,7 +360,7 @@ func failureResponse(t *testing.T, exitCode int) {
 	message := status + "Test"
 	if os.Getenv("EXECUTE_PLUGIN") == "1" {
 		r := NewResponse("")
-		r.UpdateStatus(exitCode, message)
+		r.UpdateStatus(Status(exitCode), message)
 		r.OutputAndExit()
 	}
 	cmd := exec.Command(os.Args[0], "-test.run=Test"+status+"Response")
@@ -471,3 +494,672 @@ func TestResponse_InvalidCharacterDefaultMessage(t *testing.T) {
 	res := r.GetInfo()
 	assert.True(t, res.RawOutput == "OK: test")
 }
+
+func TestResponse_UpdateStatusIfAbove(t *testing.T) {
+	r := NewResponse("")
+	assert.NoError(t, r.UpdateStatusIfAbove(5, 10, 20, "metric", false))
+	assert.Equal(t, OK, r.statusCode)
+	assert.NoError(t, r.UpdateStatusIfAbove(15, 10, 20, "metric", false))
+	assert.Equal(t, WARNING, r.statusCode)
+	assert.Empty(t, r.performanceData)
+	assert.NoError(t, r.UpdateStatusIfAbove(25, 10, 20, "metric", true))
+	assert.Equal(t, CRITICAL, r.statusCode)
+	assert.Len(t, r.performanceData, 1)
+	point := r.performanceData[performanceDataPointKey{Metric: "metric"}]
+	assert.Equal(t, "'metric'=25;~:10;~:20;;", string(point.output(false)))
+}
+
+func TestResponse_UpdateStatusIfBelow(t *testing.T) {
+	r := NewResponse("")
+	assert.NoError(t, r.UpdateStatusIfBelow(25, 20, 10, "metric", false))
+	assert.Equal(t, OK, r.statusCode)
+	assert.NoError(t, r.UpdateStatusIfBelow(15, 20, 10, "metric", true))
+	assert.Equal(t, WARNING, r.statusCode)
+	point := r.performanceData[performanceDataPointKey{Metric: "metric"}]
+	assert.Equal(t, "'metric'=15;20:;10:;;", string(point.output(false)))
+	assert.Error(t, r.UpdateStatusIfBelow(5, 20, 10, "metric", true))
+}
+
+func TestResponse_AutoOkMessage(t *testing.T) {
+	r := NewResponse("checked")
+	r.AutoOkMessage(true)
+	assert.Equal(t, "OK: checked", r.GetInfo().RawOutput)
+
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("uptime", 100).SetUnit("s")))
+	r.PrintPerformanceData(false)
+	assert.Equal(t, "OK: 1 metric collected", r.GetInfo().RawOutput)
+
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("memory_usage", 78).SetUnit("%").
+		SetThresholds(NewThresholds(nil, 80, nil, 90))))
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("cpu_usage", 20).SetUnit("%").
+		SetThresholds(NewThresholds(nil, 80, nil, 90))))
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("free_space", 30).SetUnit("%").
+		SetThresholds(NewThresholds(10, nil, 5, nil))))
+	assert.Equal(t, "OK: 4 metrics collected, all within thresholds (worst: memory_usage 78%)", r.GetInfo().RawOutput)
+
+	r.UpdateStatus(WARNING, "warning")
+	assert.Equal(t, "WARNING: warning", r.GetInfo().RawOutput)
+}
+
+func TestResponse_Messages(t *testing.T) {
+	r := NewResponse("checked")
+	assert.Equal(t, 0, r.MessageCount())
+	assert.False(t, r.HasStatus(OK))
+	r.UpdateStatus(OK, "message1")
+	r.UpdateStatus(WARNING, "message2")
+	r.UpdateStatus(OK, "message3")
+	assert.Equal(t, 3, r.MessageCount())
+	assert.True(t, r.HasStatus(OK))
+	assert.True(t, r.HasStatus(WARNING))
+	assert.False(t, r.HasStatus(CRITICAL))
+	assert.Equal(t, []OutputMessage{{Status: OK, Message: "message1"}, {Status: OK, Message: "message3"}}, r.Messages(OK))
+	assert.Empty(t, r.Messages(CRITICAL))
+}
+
+func TestResponse_WorstMessage(t *testing.T) {
+	r := NewResponse("checked")
+	_, ok := r.WorstMessage()
+	assert.False(t, ok)
+	r.UpdateStatus(OK, "message1")
+	r.UpdateStatus(WARNING, "message2")
+	r.UpdateStatus(UNKNOWN, "message3")
+	r.UpdateStatus(WARNING, "message4")
+	message, ok := r.WorstMessage()
+	assert.True(t, ok)
+	assert.Equal(t, OutputMessage{Status: UNKNOWN, Message: "message3"}, message)
+	r.UpdateStatus(CRITICAL, "message5")
+	r.UpdateStatus(CRITICAL, "message6")
+	message, _ = r.WorstMessage()
+	assert.Equal(t, OutputMessage{Status: CRITICAL, Message: "message5"}, message)
+}
+
+func TestResponse_InvalidCharacterCallback(t *testing.T) {
+	r := NewResponse("checked|")
+	err := r.SetInvalidCharacterBehavior(InvalidCharacterCallback, "")
+	assert.Error(t, err)
+	assert.Error(t, r.SetInvalidCharacterCallback(nil))
+
+	r.UpdateStatus(OK, "test|2")
+	r.UpdateStatus(OK, "secret|")
+	err = r.SetInvalidCharacterCallback(func(message OutputMessage) (OutputMessage, bool) {
+		if strings.HasPrefix(message.Message, "secret") {
+			return message, false
+		}
+		message.Message = strings.ReplaceAll(message.Message, "|", "¦")
+		return message, true
+	})
+	assert.NoError(t, err)
+	res := r.GetInfo()
+	assert.True(t, res.RawOutput == "OK: checked¦\ntest¦2")
+}
+
+func TestResponse_SetMaxMessageLength(t *testing.T) {
+	r := NewResponse("checked everything")
+	r.SetMaxMessageLength(10)
+	r.UpdateStatus(OK, "short")
+	r.UpdateStatus(OK, "äöüäöüäöüäöüäöü")
+	res := r.GetInfo()
+	assert.Equal(t, "OK: checked...\nshort\näöüäöüä...", res.RawOutput)
+
+	assert.Equal(t, "ab", truncateMessage("abcdef", 2))
+	assert.Equal(t, "abcdef", truncateMessage("abcdef", 6))
+}
+
+func TestResponse_OnStatusChange(t *testing.T) {
+	type change struct {
+		old, new Status
+		trigger  OutputMessage
+	}
+	var changes []change
+	var escalations int
+	r := NewResponse("")
+	r.OnStatusChange(func(old, new Status, trigger OutputMessage) {
+		changes = append(changes, change{old, new, trigger})
+	})
+	r.OnStatusChange(func(old, new Status, trigger OutputMessage) {
+		escalations++
+	})
+	r.UpdateStatus(OK, "message1")
+	r.UpdateStatus(WARNING, "message2")
+	r.UpdateStatus(WARNING, "message3")
+	r.UpdateStatusKV(CRITICAL, "message4", "key", "value")
+	r.UpdateStatus(UNKNOWN, "message5")
+	assert.Equal(t, []change{
+		{OK, WARNING, OutputMessage{Status: WARNING, Message: "message2"}},
+		{WARNING, CRITICAL, OutputMessage{Status: CRITICAL, Message: "message4", Fields: []Field{{Key: "key", Value: "value"}}}},
+	}, changes)
+	assert.Equal(t, 2, escalations)
+}
+
+func TestResponse_SetQuiet(t *testing.T) {
+	r := NewResponse("checked")
+	r.SetQuiet(true)
+	r.UpdateStatus(OK, "message1")
+	assert.NoError(t, r.AttachLongOutput(strings.NewReader("log"), 1024))
+	assert.Equal(t, "OK: checked", r.GetInfo().RawOutput)
+
+	r.UpdateStatus(WARNING, "message2")
+	r.UpdateStatus(CRITICAL, "message3")
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("metric", 1)))
+	res := r.GetInfo()
+	assert.Equal(t, "CRITICAL: message3 | 'metric'=1", res.RawOutput)
+	assert.Len(t, res.Messages, 3)
+}
+
+func TestResponse_SetQuiet_StatusLineOnly(t *testing.T) {
+	r := NewResponse("checked", WithQuiet())
+	r.UpdateStatus(CRITICAL, "disk /var is full\nlargest file: /var/log/syslog")
+	r.UpdateStatus(WARNING, "disk /tmp is almost full")
+	r.AddInfoMessage("checked 2 disks")
+	r.AddLongOutput("long output")
+	r.SetCheckMetadata("check_disk", "1.4.2", "")
+	r.PrintCheckMetadata(true)
+	sub := NewSubCheck()
+	sub.UpdateStatus(WARNING, "partition is almost full")
+	r.AddSubCheck("partition1", sub)
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("usage", 100).SetUnit("%")))
+	assert.Equal(t, "CRITICAL: disk /var is full | 'usage'=100%", r.String())
+
+	r.SetSummary("1 of 2 disks is full")
+	assert.Equal(t, "CRITICAL: 1 of 2 disks is full | 'usage'=100%", r.String())
+}
+
+func TestResponse_SetMaxLineWidth(t *testing.T) {
+	r := NewResponse("the first line is never wrapped")
+	r.SetMaxLineWidth(10)
+	r.UpdateStatus(OK, "short")
+	r.UpdateStatus(OK, "this line is too long for the output")
+	r.UpdateStatus(OK, "unbreakablewordislonger")
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("metric", 1)))
+	res := r.GetInfo()
+	assert.Equal(t, "OK: the first line is never wrapped\nshort\nthis line\nis too\nlong for\nthe output\nunbreakablewordislonger | 'metric'=1", res.RawOutput)
+}
+
+func TestResponse_OutputAndExitClosedStdout(t *testing.T) {
+	if os.Getenv("EXECUTE_PLUGIN") == "1" {
+		r := NewResponse("checked")
+		r.SetIgnoreSIGPIPE(true)
+		r.UpdateStatus(WARNING, "warning")
+		r.OutputAndExit()
+	}
+	reader, writer, err := os.Pipe()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, reader.Close())
+	defer writer.Close()
+
+	cmd := exec.Command(os.Args[0], "-test.run=TestResponse_OutputAndExitClosedStdout")
+	cmd.Env = append(os.Environ(), "EXECUTE_PLUGIN=1")
+	cmd.Stdout = writer
+	err = cmd.Run()
+	if exitError, ok := err.(*exec.ExitError); assert.True(t, ok, "command is expected to exit with an exit code") {
+		assert.Equal(t, int(WARNING), exitError.ExitCode())
+	}
+}
+
+func TestResponse_Output(t *testing.T) {
+	r := NewResponse("checked")
+	r.UpdateStatus(WARNING, "warning")
+	output, exitCode := r.Output()
+	assert.Equal(t, "WARNING: warning\n", string(output))
+	assert.Equal(t, 1, exitCode)
+}
+
+func TestResponse_SetOutputWriter(t *testing.T) {
+	if os.Getenv("EXECUTE_PLUGIN") == "1" {
+		r := NewResponse("checked")
+		r.SetOutputWriter(os.Stderr)
+		r.OutputAndExit()
+	}
+	cmd := exec.Command(os.Args[0], "-test.run=TestResponse_SetOutputWriter")
+	cmd.Env = append(os.Environ(), "EXECUTE_PLUGIN=1")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	assert.NoError(t, cmd.Run())
+	assert.Equal(t, "", stdout.String())
+	assert.Equal(t, "OK: checked\n", stderr.String())
+}
+
+func TestResponse_SetExitFunc(t *testing.T) {
+	var buffer bytes.Buffer
+	exitCode := -1
+	r := NewResponse("checked")
+	r.UpdateStatus(CRITICAL, "critical")
+	r.SetOutputWriter(&buffer)
+	r.SetExitFunc(func(code int) {
+		exitCode = code
+	})
+	r.OutputAndExit()
+	assert.Equal(t, 2, exitCode)
+	assert.Equal(t, "CRITICAL: critical\n", buffer.String())
+}
+
+func TestResponse_SetExitCodeMapping(t *testing.T) {
+	r := NewResponse("checked")
+	r.SetExitCodeMapping(map[Status]int{WARNING: 0, UNKNOWN: 2})
+	r.UpdateStatus(WARNING, "warning")
+	_, exitCode := r.Output()
+	assert.Equal(t, 0, exitCode)
+	r.UpdateStatus(UNKNOWN, "unknown")
+	_, exitCode = r.Output()
+	assert.Equal(t, 2, exitCode)
+	r.UpdateStatus(CRITICAL, "critical")
+	output, exitCode := r.Output()
+	assert.Equal(t, 2, exitCode)
+	assert.True(t, strings.HasPrefix(string(output), "CRITICAL: "))
+}
+
+func TestResponse_OnExit(t *testing.T) {
+	var buffer bytes.Buffer
+	var infos []ResponseInfo
+	r := NewResponse("checked")
+	r.SetOutputWriter(&buffer)
+	r.SetExitFunc(func(int) {
+		assert.Len(t, infos, 2)
+	})
+	r.OnExit(func(info ResponseInfo) {
+		infos = append(infos, info)
+	})
+	r.OnExit(func(info ResponseInfo) {
+		assert.Equal(t, "", buffer.String())
+		infos = append(infos, info)
+	})
+	r.UpdateStatus(WARNING, "warning|")
+	r.OutputAndExit()
+	assert.Len(t, infos, 2)
+	assert.Equal(t, "WARNING: warning", infos[0].RawOutput)
+	assert.Equal(t, WARNING, infos[1].StatusCode)
+}
+
+func TestResponse_SetMessageDeduplication(t *testing.T) {
+	r := NewResponse("checked")
+	r.SetMessageDeduplication(true)
+	r.SortOutputMessagesByStatus(false)
+	for i := 0; i < 42; i++ {
+		r.UpdateStatus(CRITICAL, "interface is down")
+	}
+	r.UpdateStatus(WARNING, "interface is down")
+	r.UpdateStatus(OK, "interface is up")
+	r.UpdateStatus(OK, "interface is up")
+	res := r.GetInfo()
+	assert.Equal(t, CRITICAL, res.StatusCode)
+	assert.Equal(t, "CRITICAL: interface is down (x42)\ninterface is down\ninterface is up (x2)", res.RawOutput)
+}
+
+func TestResponse_Reset(t *testing.T) {
+	r := NewResponse("checked|", WithDelimiter(" / "))
+	r.UpdateStatus(CRITICAL, "critical")
+	r.SetSummary("summary")
+	r.AddLongOutput("long output")
+	r.AddSubCheck("sub", NewSubCheck())
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("metric", 1)))
+	r.GetInfo()
+
+	r.Reset()
+	assert.Equal(t, OK, r.GetStatusCode())
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("metric", 2)))
+	r.UpdateStatus(OK, "message")
+	assert.Equal(t, "OK: checked / message | 'metric'=2", r.GetInfo().RawOutput)
+}
+
+func TestResponse_Clone(t *testing.T) {
+	r := NewResponse("checked", WithDelimiter(" / "))
+	r.SetExitCodeMapping(map[Status]int{WARNING: 0})
+	r.UpdateStatus(OK, "message1")
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("metric", 1)))
+
+	clone := r.Clone()
+	clone.UpdateStatus(WARNING, "message2")
+	assert.NoError(t, clone.AddPerformanceDataPoint(NewPerformanceDataPoint("metric2", 2)))
+	clone.SetExitCodeMapping(map[Status]int{WARNING: 5})
+
+	assert.Equal(t, "OK: checked / message1 | 'metric'=1", r.GetInfo().RawOutput)
+	assert.Len(t, clone.performanceData, 2)
+	assert.Equal(t, WARNING, clone.GetStatusCode())
+	assert.Equal(t, 0, r.exitCodeMapping[WARNING])
+	_, exitCode := clone.Output()
+	assert.Equal(t, 5, exitCode)
+}
+
+func TestResponse_SetStatusLabels(t *testing.T) {
+	r := NewResponse("geprüft")
+	r.SetStatusLabels(map[Status]string{WARNING: "WARNUNG", CRITICAL: "KRITISCH"})
+	assert.Equal(t, "OK: geprüft", r.GetInfo().RawOutput)
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("temperatur", 30).
+		SetThresholds(NewThresholds(nil, 25, nil, 35))))
+	r.PrintPerformanceData(false)
+	assert.Equal(t, "WARNUNG: temperatur is outside of WARNUNG threshold", r.GetInfo().RawOutput)
+}
+
+func TestResponse_String(t *testing.T) {
+	r := NewResponse("checked")
+	r.UpdateStatus(WARNING, "warning|")
+	assert.Equal(t, "WARNING: warning", r.String())
+	assert.Equal(t, []byte("WARNING: warning"), r.Bytes())
+}
+
+func TestResponse_SetDryRun(t *testing.T) {
+	var buffer bytes.Buffer
+	called := false
+	r := NewResponse("checked", WithOutputWriter(&buffer), WithExitFunc(func(int) {
+		called = true
+	}))
+	r.OnExit(func(ResponseInfo) {
+		called = true
+	})
+	r.SetDryRun(true)
+	r.UpdateStatus(CRITICAL, "critical")
+	r.OutputAndExit()
+	assert.False(t, called)
+	assert.Equal(t, "CRITICAL: critical\n", buffer.String())
+}
+
+func TestResponse_SetOutputTemplate(t *testing.T) {
+	r := NewResponse("checked")
+	r.UpdateStatus(WARNING, "warning")
+	r.UpdateStatus(OK, "ok")
+	tmpl := template.Must(template.New("output").Parse("[{{.StatusCode}}] {{len .Messages}} findings{{range .Messages}}\n- {{.Message}}{{end}}"))
+	r.SetOutputTemplate(tmpl)
+	assert.Equal(t, "[WARNING] 2 findings\n- warning\n- ok", r.GetInfo().RawOutput)
+
+	r.SetOutputTemplate(template.Must(template.New("output").Parse("{{.Invalid}}")))
+	assert.Equal(t, "WARNING: warning\nok", r.String())
+}
+
+func TestResponse_SetLogger(t *testing.T) {
+	var buffer bytes.Buffer
+	r := NewResponse("checked")
+	r.SetLogger(slog.New(slog.NewTextHandler(&buffer, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	r.UpdateStatus(OK, "ok")
+	assert.Equal(t, "", buffer.String())
+
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("metric", 30).
+		SetThresholds(NewThresholds(nil, 25, nil, 35))))
+	assert.Contains(t, buffer.String(), `msg="threshold violated" name=metric value=30 status=WARNING`)
+	assert.Contains(t, buffer.String(), `msg="status changed" old=OK new=WARNING message="metric is outside of WARNING threshold"`)
+
+	buffer.Reset()
+	assert.NoError(t, r.SetInvalidCharacterBehavior(InvalidCharacterRemoveMessage, ""))
+	r.UpdateStatus(OK, "invalid|")
+	r.validate()
+	assert.Contains(t, buffer.String(), `msg="invalid character in output message" status=OK message=invalid|`)
+	assert.Contains(t, buffer.String(), `msg="output message dropped" status=OK message=invalid|`)
+}
+
+func TestResponse_UpdateStatusOnErrors(t *testing.T) {
+	r := NewResponse("checked")
+	assert.False(t, r.UpdateStatusOnErrors(CRITICAL, "", nil, nil))
+	assert.Equal(t, OK, r.GetStatusCode())
+
+	err := stderrors.Join(stderrors.New("disk1 failed"), nil, stderrors.Join(stderrors.New("disk2 failed"), stderrors.New("disk3 failed")))
+	assert.True(t, r.UpdateStatusOnErrors(WARNING, "", err, stderrors.New("disk4 failed")))
+	assert.True(t, r.UpdateStatusOnErrors(CRITICAL, "check failed", stderrors.New("timeout")))
+	assert.Equal(t, CRITICAL, r.GetStatusCode())
+	assert.Equal(t, []OutputMessage{
+		{Status: WARNING, Message: "disk1 failed"},
+		{Status: WARNING, Message: "disk2 failed"},
+		{Status: WARNING, Message: "disk3 failed"},
+		{Status: WARNING, Message: "disk4 failed"},
+		{Status: CRITICAL, Message: "check failed (error: timeout)"},
+	}, r.outputMessages)
+}
+
+func TestResponse_SetMaxStatus(t *testing.T) {
+	r := NewResponse("checked")
+	r.SetMaxStatus(WARNING)
+	r.UpdateStatus(CRITICAL, "disk full")
+	output, exitCode := r.Output()
+	assert.Equal(t, "WARNING: disk full\n", string(output))
+	assert.Equal(t, 1, exitCode)
+	assert.Equal(t, CRITICAL, r.GetStatusCode())
+
+	info := r.GetInfo()
+	assert.Equal(t, WARNING, info.StatusCode)
+	assert.Equal(t, CRITICAL, info.ComputedStatusCode)
+
+	r = NewResponse("checked")
+	r.SetMaxStatus(WARNING)
+	_, exitCode = r.Output()
+	assert.Equal(t, 0, exitCode)
+}
+
+func TestResponse_ForceStatus(t *testing.T) {
+	r := NewResponse("checked")
+	r.UpdateStatus(WARNING, "disk almost full")
+	r.ForceStatus(CRITICAL)
+	output, exitCode := r.Output()
+	assert.Equal(t, "CRITICAL: disk almost full\n", string(output))
+	assert.Equal(t, 2, exitCode)
+
+	r.SetMaxStatus(OK)
+	info := r.GetInfo()
+	assert.Equal(t, CRITICAL, info.StatusCode)
+	assert.Equal(t, WARNING, info.ComputedStatusCode)
+
+	r.ForceStatus(Status(42))
+	_, exitCode = r.Output()
+	assert.Equal(t, 3, exitCode)
+}
+
+func TestResponse_HideDefaultOkMessage(t *testing.T) {
+	r := NewResponse("everything checked!")
+	r.HideDefaultOkMessage(true)
+	r.UpdateStatus(OK, "disk /var is fine")
+	r.UpdateStatus(OK, "disk /tmp is fine")
+	assert.Equal(t, "OK: disk /var is fine\ndisk /tmp is fine", r.String())
+
+	r.SetQuiet(true)
+	assert.Equal(t, "OK: disk /var is fine", r.String())
+}
+
+func TestResponse_HideRedundantDefaultOkMessage(t *testing.T) {
+	r := NewResponse("everything checked!")
+	r.HideRedundantDefaultOkMessage(true)
+	assert.Equal(t, "OK: everything checked!", r.String())
+
+	r.UpdateStatus(OK, "disk /var is fine")
+	assert.Equal(t, "OK: disk /var is fine", r.String())
+
+	r.HideRedundantDefaultOkMessage(false)
+	assert.Equal(t, "OK: everything checked!\ndisk /var is fine", r.String())
+}
+
+func TestResponse_PrefixMessagesWithStatus(t *testing.T) {
+	r := NewResponse("everything checked!")
+	r.PrefixMessagesWithStatus(true)
+	r.UpdateStatus(OK, "disk /tmp is fine")
+	assert.Equal(t, "OK: everything checked!\n[OK] disk /tmp is fine", r.String())
+
+	r.UpdateStatus(WARNING, "disk /var is 85% full")
+	r.UpdateStatus(CRITICAL, "disk /home is 99% full")
+	assert.Equal(t, "CRITICAL: [CRITICAL] disk /home is 99% full\n[WARNING] disk /var is 85% full\n[OK] disk /tmp is fine", r.String())
+
+	r.SetQuiet(true)
+	assert.Equal(t, "CRITICAL: [CRITICAL] disk /home is 99% full", r.String())
+}
+
+func TestResponse_AddInfoMessage(t *testing.T) {
+	r := NewResponse("everything checked!")
+	r.AddInfoMessage("checked 12 disks")
+	r.AddInfoMessage("took 3|s")
+	assert.Equal(t, "OK: everything checked!", r.String())
+	assert.Equal(t, OK, r.GetStatusCode())
+	assert.Equal(t, 0, r.MessageCount())
+
+	r.SetVerbose(true)
+	assert.Equal(t, "OK: everything checked!\nchecked 12 disks\ntook 3s", r.String())
+
+	r.SetVerbose(false)
+	r.UpdateStatus(WARNING, "disk /var is 85% full")
+	assert.Equal(t, "WARNING: disk /var is 85% full\nchecked 12 disks\ntook 3s", r.String())
+
+	r.SetQuiet(true)
+	assert.Equal(t, "WARNING: disk /var is 85% full", r.String())
+
+	r.Reset()
+	r.SetQuiet(false)
+	r.SetSummary("summary")
+	r.UpdateStatus(CRITICAL, "")
+	r.AddInfoMessage("checked 12 disks")
+	assert.Equal(t, "CRITICAL: summary\nchecked 12 disks", r.String())
+}
+
+func TestResponse_SetMaxMessages(t *testing.T) {
+	r := NewResponse("everything checked!")
+	r.SetMaxMessages(2)
+	r.SortOutputMessagesByStatus(false)
+	r.UpdateStatus(WARNING, "warning 1")
+	r.UpdateStatus(CRITICAL, "critical 1")
+	r.UpdateStatus(OK, "ok 1")
+	r.UpdateStatus(WARNING, "warning 2")
+	r.UpdateStatus(CRITICAL, "critical 2")
+	r.UpdateStatus(CRITICAL, "critical 3")
+	assert.Equal(t, "CRITICAL: critical 1\ncritical 2\n... and 4 more (1 critical, 2 warning, 1 ok)", r.String())
+	assert.Equal(t, 2, r.MessageCount())
+
+	// validating again must not lose the number of omitted messages
+	r.UpdateStatus(WARNING, "warning 3")
+	assert.Equal(t, "CRITICAL: critical 1\ncritical 2\n... and 5 more (1 critical, 3 warning, 1 ok)", r.String())
+
+	r.Reset()
+	r.UpdateStatus(WARNING, "warning 1")
+	assert.Equal(t, "WARNING: warning 1", r.String())
+}
+
+func TestResponse_AllowDependentStatus(t *testing.T) {
+	r := NewResponse("checked")
+	r.UpdateStatus(DEPENDENT, "parent host is down")
+	assert.Equal(t, UNKNOWN, r.GetStatusCode())
+
+	r = NewResponse("checked")
+	r.AllowDependentStatus(true)
+	r.UpdateStatus(WARNING, "disk almost full")
+	r.UpdateStatus(DEPENDENT, "parent host is down")
+	r.UpdateStatus(UNKNOWN, "no data")
+	assert.Equal(t, DEPENDENT, r.GetStatusCode())
+	output, exitCode := r.Output()
+	assert.Equal(t, "DEPENDENT: parent host is down\nno data\ndisk almost full\n", string(output))
+	assert.Equal(t, 4, exitCode)
+
+	r.UpdateStatus(CRITICAL, "disk full")
+	assert.Equal(t, CRITICAL, r.GetStatusCode())
+	message, ok := r.WorstMessage()
+	assert.True(t, ok)
+	assert.Equal(t, CRITICAL, message.Status)
+}
+
+func TestResponse_RemoveMessages(t *testing.T) {
+	r := NewResponse("checked")
+	r.UpdateStatus(OK, "disk /var is fine")
+	r.UpdateStatus(CRITICAL, "snapshot volume /snap1 is full")
+	r.UpdateStatus(WARNING, "disk /tmp is 85% full")
+	r.UpdateStatus(CRITICAL, "snapshot volume /snap2 is full")
+	assert.Len(t, r.Messages(), 4)
+	assert.Len(t, r.Messages(WARNING, CRITICAL), 3)
+
+	removed := r.RemoveMessages(func(message OutputMessage) bool {
+		return strings.HasPrefix(message.Message, "snapshot volume")
+	})
+	assert.Equal(t, 2, removed)
+	assert.Equal(t, WARNING, r.GetStatusCode())
+	assert.Equal(t, "WARNING: disk /tmp is 85% full\ndisk /var is fine", r.String())
+
+	// status updates without a message are kept
+	r.UpdateStatus(UNKNOWN, "")
+	r.RemoveMessages(func(message OutputMessage) bool {
+		return message.Status == WARNING
+	})
+	assert.Equal(t, UNKNOWN, r.GetStatusCode())
+}
+
+func TestResponse_ReplaceMessage(t *testing.T) {
+	r := NewResponse("checked")
+	r.UpdateStatus(OK, "disk /var is fine")
+	r.UpdateStatus(CRITICAL, "disk /tmp is 99% full")
+	replaced := r.ReplaceMessage(func(message OutputMessage) bool {
+		return strings.Contains(message.Message, "/tmp")
+	}, OutputMessage{Status: WARNING, Message: "disk /tmp is 99% full (tmpfs)"})
+	assert.Equal(t, 1, replaced)
+	assert.Equal(t, WARNING, r.GetStatusCode())
+	assert.Equal(t, []OutputMessage{
+		{Status: OK, Message: "disk /var is fine"},
+		{Status: WARNING, Message: "disk /tmp is 99% full (tmpfs)"},
+	}, r.Messages())
+}
+
+func TestResponse_SetDefaultOkMessage(t *testing.T) {
+	r := NewResponse("checked")
+	r.SetDefaultOkMessagef("%d of %d disks checked", 5, 5)
+	assert.Equal(t, "OK: 5 of 5 disks checked", r.String())
+
+	version := "unknown"
+	r.SetDefaultOkMessageFunc(func() string {
+		return "firmware " + version
+	})
+	version = "1.4.2"
+	assert.Equal(t, "OK: firmware 1.4.2", r.String())
+
+	r.SetDefaultOkMessage("everything checked!")
+	r.Reset()
+	assert.Equal(t, "OK: everything checked!", r.String())
+}
+
+func TestResponse_IndentContinuationLines(t *testing.T) {
+	r := NewResponse("checked")
+	r.UpdateStatus(WARNING, "disk /var is 85% full\nlargest directory: /var/log")
+	r.AddLongOutput("mount options:\nrw,noatime")
+	assert.Equal(t, "WARNING: disk /var is 85% full\nlargest directory: /var/log\nmount options:\nrw,noatime", r.String())
+
+	r.IndentContinuationLines(true)
+	assert.Equal(t, "WARNING: disk /var is 85% full\n    largest directory: /var/log\nmount options:\n    rw,noatime", r.String())
+
+	r.SetIndentation("  - ")
+	assert.Equal(t, "WARNING: disk /var is 85% full\n  - largest directory: /var/log\nmount options:\n  - rw,noatime", r.String())
+}
+
+func TestResponse_GetPerformanceDataPoint(t *testing.T) {
+	r := NewResponse("checked")
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("c", 1)))
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("a", 2).SetLabel("x")))
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("b", 3)))
+
+	point := r.GetPerformanceDataPoint("a", "x")
+	if assert.NotNil(t, point) {
+		assert.Equal(t, 2, point.Value)
+		point.SetUnit("%")
+		assert.Equal(t, "", r.GetPerformanceDataPoint("a", "x").Unit)
+		assert.NoError(t, r.AddOrReplacePerformanceDataPoint(point))
+		assert.Equal(t, "%", r.GetPerformanceDataPoint("a", "x").Unit)
+	}
+	assert.Nil(t, r.GetPerformanceDataPoint("a", ""))
+
+	assert.True(t, r.RemovePerformanceDataPoint("c", ""))
+	assert.False(t, r.RemovePerformanceDataPoint("c", ""))
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("a", 4)))
+	assert.Equal(t, "'a_x'=2% 'b'=3 'a'=4", r.performanceDataOutput())
+}
+
+func TestResponse_AddPerformanceDataPoints(t *testing.T) {
+	r := NewResponse("checked")
+	err := r.AddPerformanceDataPoints(
+		NewPerformanceDataPoint("load1", 0.5),
+		NewPerformanceDataPoint("load5", 2).SetMax(1),
+		nil,
+		NewPerformanceDataPoint("load1", 0.6),
+		NewPerformanceDataPoint("load15", 0.7),
+	)
+	if assert.Error(t, err) {
+		lines := strings.Split(err.Error(), "\n")
+		if assert.Len(t, lines, 3) {
+			assert.True(t, strings.HasPrefix(lines[0], "performance data point 'load5': "))
+			assert.Equal(t, "performance data point 2 is nil", lines[1])
+			assert.True(t, strings.HasPrefix(lines[2], "performance data point 'load1': "))
+		}
+	}
+	assert.Equal(t, "'load1'=0.5 'load15'=0.7", r.performanceDataOutput())
+
+	assert.NoError(t, r.AddPerformanceDataPoints(NewPerformanceDataPoint("uptime", 1)))
+	assert.NoError(t, r.AddPerformanceDataPoints())
+}
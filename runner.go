@@ -0,0 +1,98 @@
+package monitoringplugin
+
+import (
+	"sync"
+)
+
+/*
+RunParallel runs independent check tasks in a pool of at most concurrency workers. Every task gets its own Response
+with the configuration of this Response (without hooks and sinks), which is merged into this Response after all tasks
+are finished, in the order the tasks were passed, so the output is deterministic regardless of which task finished
+first. If a task returns an error, an UNKNOWN message containing the error is added.
+Usage:
+	Response.RunParallel(4,
+		func(r *Response) error {
+			//check interface 1...
+		},
+		func(r *Response) error {
+			//check interface 2...
+		},
+	)
+*/
+func (r *Response) RunParallel(concurrency int, tasks ...func(*Response) error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]*Response, len(tasks))
+	for i := range results {
+		results[i] = r.taskResponse()
+	}
+	errs := make([]error, len(tasks))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency && w < len(tasks); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				errs[i] = tasks[i](results[i])
+			}
+		}()
+	}
+	for i := range tasks {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	for i, result := range results {
+		r.merge(result)
+		r.UpdateStatusOnError(errs[i], UNKNOWN, "check task failed", true)
+	}
+}
+
+// taskResponse returns an empty Response with the configuration of this Response for a check task. The metric prefix
+// is not inherited, because it is applied when the task response is merged.
+func (r *Response) taskResponse() *Response {
+	task := r.Clone()
+	task.performanceDataStream = nil
+	task.metricPrefix = ""
+	task.onStatusChange = nil
+	task.exitHooks = nil
+	task.sinks = nil
+	task.Reset()
+	return task
+}
+
+// merge adds the status, messages, info messages, performance data, sub-checks, long output and summary of another
+// Response. A summary of the other Response replaces the summary of this Response. The performance data points are
+// added in the order they were added to the other Response, their thresholds were already checked by the other
+// Response.
+func (r *Response) merge(other *Response) {
+	defer r.beginModification()()
+	if err := r.checkFinalized(); err != nil {
+		r.debug("check task result ignored", "error", err)
+		return
+	}
+	for _, message := range other.outputMessages {
+		r.updateStatus(message)
+	}
+	r.updateStatus(OutputMessage{Status: other.statusCode})
+	r.violations = append(r.violations, other.violations...)
+	for _, point := range other.performanceData.getInfo() {
+		point := point
+		if err := r.AddPerformanceDataPointNoCheck(&point); err != nil {
+			r.UpdateStatus(UNKNOWN, "failed to add performance data point: "+err.Error())
+		}
+	}
+	for _, point := range other.derivedPerformanceData {
+		r.addDerivedPerformanceDataPoint(point)
+	}
+	r.subChecks = append(r.subChecks, other.subChecks...)
+	r.longOutput = append(r.longOutput, other.longOutput...)
+	r.infoMessages = append(r.infoMessages, other.infoMessages...)
+	if other.summary != "" {
+		r.summary = other.summary
+	}
+}
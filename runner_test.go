@@ -0,0 +1,95 @@
+package monitoringplugin
+
+import (
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestResponse_RunParallel(t *testing.T) {
+	r := NewResponse("checked")
+	r.SortOutputMessagesByStatus(false)
+	var tasks []func(*Response) error
+	for i := 0; i < 10; i++ {
+		i := i
+		tasks = append(tasks, func(r *Response) error {
+			time.Sleep(time.Duration(10-i) * time.Millisecond)
+			r.UpdateStatus(OK, "task "+strconv.Itoa(i))
+			return r.AddPerformanceDataPoint(NewPerformanceDataPoint("metric", i).SetLabel(strconv.Itoa(i)))
+		})
+	}
+	tasks = append(tasks, func(r *Response) error {
+		r.UpdateStatus(WARNING, "")
+		return errors.New("connection refused")
+	})
+	r.RunParallel(3, tasks...)
+
+	res := r.GetInfo()
+	assert.Equal(t, UNKNOWN, res.StatusCode)
+	assert.Len(t, res.PerformanceData, 10)
+	assert.Len(t, res.Messages, 11)
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, OutputMessage{Status: OK, Message: "task " + strconv.Itoa(i)}, res.Messages[i])
+	}
+	assert.Equal(t, OutputMessage{Status: UNKNOWN, Message: "check task failed (error: connection refused)"}, res.Messages[10])
+
+	r = NewResponse("checked")
+	r.RunParallel(0, func(r *Response) error {
+		return r.AddPerformanceDataPoint(NewPerformanceDataPoint("metric", 1))
+	}, func(r *Response) error {
+		return r.AddPerformanceDataPoint(NewPerformanceDataPoint("metric", 2))
+	})
+	assert.Equal(t, UNKNOWN, r.GetStatusCode())
+	assert.Len(t, r.performanceData, 1)
+}
+
+func TestResponse_RunParallelConfiguration(t *testing.T) {
+	r := NewResponse("checked")
+	r.SetMetricPrefix("if_")
+	r.SetPerfDataSanitization(PerfDataSanitizationReplace)
+	var statusChanges int
+	r.OnStatusChange(func(old, new Status, trigger OutputMessage) {
+		statusChanges++
+	})
+	r.RunParallel(2, func(r *Response) error {
+		for i := 0; i < 20; i++ {
+			if err := r.AddPerformanceDataPoint(NewPerformanceDataPoint("in octets", i).SetLabel(strconv.Itoa(i))); err != nil {
+				return err
+			}
+		}
+		return r.AddPerformanceDataPoint(NewPerformanceDataPoint("errors", 5).SetThresholds(NewThresholds(nil, 1, nil, 10)))
+	})
+
+	res := r.GetInfo()
+	assert.Equal(t, WARNING, res.StatusCode)
+	assert.Len(t, res.Messages, 1)
+	assert.Len(t, res.Violations, 1)
+	assert.Equal(t, 1, statusChanges)
+	if assert.Len(t, res.PerformanceData, 21) {
+		for i := 0; i < 20; i++ {
+			assert.Equal(t, "if_in_octets", res.PerformanceData[i].Metric)
+			assert.Equal(t, strconv.Itoa(i), res.PerformanceData[i].Label)
+		}
+		assert.Equal(t, "if_errors", res.PerformanceData[20].Metric)
+	}
+
+	r.Finalize()
+	r.RunParallel(1, func(r *Response) error {
+		r.UpdateStatus(CRITICAL, "late")
+		return nil
+	})
+	assert.Equal(t, WARNING, r.GetStatusCode())
+}
+
+func TestResponse_RunParallelInfoMessages(t *testing.T) {
+	r := NewResponse("checked")
+	r.SetVerbose(true)
+	r.RunParallel(1, func(r *Response) error {
+		r.AddInfoMessage("disk1 has 10 partitions")
+		r.SetSummary("1 disk checked")
+		return nil
+	})
+	assert.Equal(t, "OK: 1 disk checked\ndisk1 has 10 partitions", r.String())
+}
@@ -0,0 +1,68 @@
+package monitoringplugin
+
+import "github.com/pkg/errors"
+
+/*
+Series is a family of per-instance performance data points (e.g. per interface or per disk) that share their metric,
+unit, min, max and thresholds and differ only in their label and value.
+Usage:
+	series := NewSeries("if_octets").WithUnit("c")
+	for name, octets := range interfaces {
+		err := series.Add(Response, name, octets)
+		...
+	}
+*/
+type Series struct {
+	template *PerformanceDataPoint
+	labels   map[string]bool
+}
+
+// NewSeries creates a new Series with the given metric.
+func NewSeries(metric string) *Series {
+	return &Series{
+		template: NewPerformanceDataPoint(metric, nil),
+		labels:   make(map[string]bool),
+	}
+}
+
+// WithUnit sets the unit of all performance data points of the series.
+func (s *Series) WithUnit(unit string) *Series {
+	s.template.SetUnit(unit)
+	return s
+}
+
+// WithMin sets the minimum value of all performance data points of the series.
+func (s *Series) WithMin(min interface{}) *Series {
+	s.template.SetMin(min)
+	return s
+}
+
+// WithMax sets the maximum value of all performance data points of the series.
+func (s *Series) WithMax(max interface{}) *Series {
+	s.template.SetMax(max)
+	return s
+}
+
+// WithThresholds sets the thresholds of all performance data points of the series.
+func (s *Series) WithThresholds(thresholds Thresholds) *Series {
+	s.template.SetThresholds(thresholds)
+	return s
+}
+
+// Add adds a performance data point with the given label and value to the response. It returns an error if the label
+// was already added to the series or the performance data point can not be added.
+func (s *Series) Add(r *Response, label string, value interface{}) error {
+	if s.labels[label] {
+		return errors.Errorf("label '%s' was already added to series '%s'", label, s.template.Metric)
+	}
+	if err := r.AddPerformanceDataPoint(s.template.WithLabel(label).SetValue(value)); err != nil {
+		return errors.Wrapf(err, "failed to add label '%s' to series '%s'", label, s.template.Metric)
+	}
+	s.labels[label] = true
+	return nil
+}
+
+// Len returns the number of labels that were added to the series.
+func (s *Series) Len() int {
+	return len(s.labels)
+}
@@ -0,0 +1,26 @@
+package monitoringplugin
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSeries(t *testing.T) {
+	r := NewResponse("checked")
+	series := NewSeries("if_octets").WithUnit("c")
+	assert.NoError(t, series.Add(r, "eth0", 1234))
+	assert.NoError(t, series.Add(r, "eth1", 5678))
+	assert.Error(t, series.Add(r, "eth0", 1))
+	assert.Equal(t, 2, series.Len())
+
+	usage := NewSeries("disk_usage").WithUnit("%").WithMin(0).WithMax(100).
+		WithThresholds(NewThresholds(nil, 80, nil, 90))
+	assert.NoError(t, usage.Add(r, "/", 50))
+	assert.NoError(t, usage.Add(r, "/var", 95))
+	assert.Error(t, usage.Add(r, "/tmp", 150))
+	assert.Equal(t, 2, usage.Len())
+
+	assert.Equal(t, CRITICAL, r.GetStatusCode())
+	assert.Equal(t, "'if_octets_eth0'=1234c 'if_octets_eth1'=5678c 'disk_usage_/'=50%;~:80;~:90;0;100 "+
+		"'disk_usage_/var'=95%;~:80;~:90;0;100", r.performanceDataOutput())
+}
@@ -0,0 +1,78 @@
+package monitoringplugin
+
+import (
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"time"
+)
+
+// states of the signal handling of a Response
+const (
+	signalNone int32 = iota
+	signalHandling
+	signalHandled
+)
+
+/*
+HandleSignals handles the given signals by adding an UNKNOWN message to the response and exiting via OutputAndExit(),
+so the current partial result is printed instead of empty output when the monitoring core kills the check plugin.
+The returned function stops handling the signals.
+The signal is handled on a goroutine of its own. Status updates, performance data points, sub-checks, info messages,
+long output and the summary that are added while the signal is handled are rejected like after Finalize(), the
+handler waits for modifications that are already running, so hooks that are called by them (e.g. OnStatusChange)
+must not block. A concurrent call of OutputAndExit() waits until the signal handler wrote the output. Configuration
+setters are not synchronized, they must not be called after the signals are handled.
+Usage:
+	Response.HandleSignals(syscall.SIGTERM, syscall.SIGALRM)
+*/
+func (r *Response) HandleSignals(signals ...os.Signal) (stop func()) {
+	c := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(c, signals...)
+	go func() {
+		select {
+		case sig := <-c:
+			r.handleSignal(sig)
+		case <-done:
+		}
+	}()
+	return func() {
+		signal.Stop(c)
+		close(done)
+	}
+}
+
+func (r *Response) handleSignal(sig os.Signal) {
+	if !atomic.CompareAndSwapInt32(&r.signalState, signalNone, signalHandling) {
+		return
+	}
+	defer atomic.StoreInt32(&r.signalState, signalHandled)
+	// new modifications are rejected now, the running ones have to finish before the output can be rendered
+	for atomic.LoadInt32(&r.modifications) > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	r.updateStatus(OutputMessage{Status: UNKNOWN, Message: "plugin terminated by signal: " + sig.String()})
+	r.outputAndExit()
+}
+
+// beginModification marks a modification of the Response as running until the returned function is called, so a
+// signal handler waits for it before it renders the output.
+func (r *Response) beginModification() (end func()) {
+	atomic.AddInt32(&r.modifications, 1)
+	return func() {
+		atomic.AddInt32(&r.modifications, -1)
+	}
+}
+
+// handlingSignal checks if a signal is handled or was handled by HandleSignals(...os.Signal).
+func (r *Response) handlingSignal() bool {
+	return atomic.LoadInt32(&r.signalState) != signalNone
+}
+
+// waitForSignalHandler waits until the signal handler wrote the output.
+func (r *Response) waitForSignalHandler() {
+	for atomic.LoadInt32(&r.signalState) != signalHandled {
+		time.Sleep(time.Millisecond)
+	}
+}
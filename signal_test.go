@@ -0,0 +1,91 @@
+//go:build !plan9 && !js && !wasip1
+
+package monitoringplugin
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestResponse_HandleSignals(t *testing.T) {
+	var buffer bytes.Buffer
+	exitCode := -1
+	r := NewResponse("checked")
+	r.SetOutputWriter(&buffer)
+	r.SetExitFunc(func(code int) {
+		exitCode = code
+	})
+	stop := r.HandleSignals(syscall.SIGTERM)
+	stop()
+
+	r.UpdateStatus(OK, "partial result")
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("metric", 1)))
+	r.handleSignal(syscall.SIGTERM)
+	assert.Equal(t, 3, exitCode)
+	assert.Equal(t, "UNKNOWN: plugin terminated by signal: terminated\npartial result | 'metric'=1\n", buffer.String())
+}
+
+func TestResponse_HandleSignals_Deliver(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("signals can not be sent to the own process on windows")
+	}
+	var buffer bytes.Buffer
+	exited := make(chan int, 1)
+	r := NewResponse("checked")
+	r.SetOutputWriter(&buffer)
+	r.SetExitFunc(func(code int) {
+		exited <- code
+	})
+	r.UpdateStatus(WARNING, "partial result")
+	stop := r.HandleSignals(syscall.SIGHUP)
+	defer stop()
+
+	process, err := os.FindProcess(os.Getpid())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, process.Signal(syscall.SIGHUP))
+	select {
+	case exitCode := <-exited:
+		assert.Equal(t, 3, exitCode)
+		assert.Equal(t, UNKNOWN, r.GetStatusCode())
+		assert.Equal(t, "UNKNOWN: plugin terminated by signal: hangup\npartial result\n", buffer.String())
+	case <-time.After(5 * time.Second):
+		t.Fatal("signal was not handled")
+	}
+}
+
+func TestResponse_HandleSignals_ConcurrentModifications(t *testing.T) {
+	var buffer bytes.Buffer
+	exited := make(chan int, 2)
+	r := NewResponse("checked")
+	r.SetOutputWriter(&buffer)
+	r.SetExitFunc(func(code int) {
+		exited <- code
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			r.UpdateStatus(OK, "partial result "+strconv.Itoa(i))
+			_ = r.AddPerformanceDataPoint(NewPerformanceDataPoint("metric_"+strconv.Itoa(i), i))
+			r.AddInfoMessage("info")
+		}
+		r.OutputAndExit()
+	}()
+	r.handleSignal(syscall.SIGTERM)
+	<-done
+
+	assert.Equal(t, 3, <-exited)
+	assert.Len(t, exited, 0)
+	assert.True(t, strings.HasPrefix(buffer.String(), "UNKNOWN: plugin terminated by signal: terminated\n"))
+	assert.Equal(t, 1, strings.Count(buffer.String(), "UNKNOWN"))
+}
@@ -0,0 +1,14 @@
+//go:build !plan9 && !js && !wasip1
+
+package monitoringplugin
+
+import (
+	"os/signal"
+	"syscall"
+)
+
+// ignoreSIGPIPE ignores SIGPIPE process-wide, so writing to a closed stdout returns an error instead of killing the
+// process.
+func ignoreSIGPIPE() {
+	signal.Ignore(syscall.SIGPIPE)
+}
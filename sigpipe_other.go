@@ -0,0 +1,6 @@
+//go:build plan9 || js || wasip1
+
+package monitoringplugin
+
+// ignoreSIGPIPE does nothing on platforms without SIGPIPE.
+func ignoreSIGPIPE() {}
@@ -0,0 +1,19 @@
+//go:build !plan9 && !js && !wasip1
+
+package monitoringplugin
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"os/signal"
+	"syscall"
+	"testing"
+)
+
+func TestResponse_SetOutputWriter_SIGPIPE(t *testing.T) {
+	var buffer bytes.Buffer
+	r := NewResponse("checked", WithOutputWriter(&buffer), WithExitFunc(func(int) {}), WithIgnoreSIGPIPE())
+	r.OutputAndExit()
+	assert.Equal(t, "OK: checked\n", buffer.String())
+	assert.False(t, signal.Ignored(syscall.SIGPIPE))
+}
@@ -0,0 +1,27 @@
+package monitoringplugin
+
+import (
+	"fmt"
+	"os"
+)
+
+// Sink receives the final result of the check plugin before it exits, e.g. to keep a history of the results or to
+// forward them to another system.
+type Sink interface {
+	Write(info ResponseInfo) error
+}
+
+// AddSink adds a Sink that receives the final result of the check plugin when OutputAndExit() is called.
+func (r *Response) AddSink(sink Sink) {
+	r.sinks = append(r.sinks, sink)
+}
+
+// writeSinks writes the final result to all sinks. Errors are printed to stderr, because they must not affect the
+// output and exit code of the check plugin.
+func (r *Response) writeSinks(info ResponseInfo) {
+	for _, sink := range r.sinks {
+		if err := sink.Write(info); err != nil {
+			fmt.Fprintln(os.Stderr, "failed to write result to sink:", err)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !unix
+
+package state
+
+import "os"
+
+// lockFile does nothing on platforms without flock, the state file is still replaced atomically.
+func lockFile(*os.File, bool) error {
+	return nil
+}
+
+func unlockFile(*os.File) error {
+	return nil
+}
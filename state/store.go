@@ -0,0 +1,181 @@
+// Package state provides persistence of arbitrary data (counters, baselines, flap history, etc.) between invocations of
+// a check plugin.
+package state
+
+import (
+	"encoding/json"
+	"github.com/pkg/errors"
+	"os"
+	"path/filepath"
+)
+
+// DefaultDirectory is the directory the state files are stored in by NewFileStore.
+const DefaultDirectory = "/var/lib/monitoringplugin"
+
+/*
+Store persists data between invocations of a check plugin. Load decodes the data that was saved under the key into v
+and returns false if there is no data for the key. The data must be encodable as JSON.
+*/
+type Store interface {
+	Load(key string, v interface{}) (bool, error)
+	Save(key string, v interface{}) error
+}
+
+/*
+FileStore is a Store that keeps all data in one JSON file. Concurrent invocations of the check plugin are synchronized
+with a lock file and the JSON file is replaced atomically, so it is never read half-written.
+*/
+type FileStore struct {
+	path string
+}
+
+/*
+NewFileStore creates a FileStore with the file "<name>.json" in the DefaultDirectory, which is usually the name of the
+check plugin.
+Usage:
+	store := state.NewFileStore("check_interfaces")
+*/
+func NewFileStore(name string) *FileStore {
+	return NewFileStoreAt(filepath.Join(DefaultDirectory, name+".json"))
+}
+
+// NewFileStoreAt creates a FileStore with the given file. Its directory is created when data is saved.
+func NewFileStoreAt(path string) *FileStore {
+	return &FileStore{
+		path: path,
+	}
+}
+
+// Path returns the path of the JSON file.
+func (s *FileStore) Path() string {
+	return s.path
+}
+
+// Load decodes the data that was saved under the key into v. It returns false if there is no data for the key.
+func (s *FileStore) Load(key string, v interface{}) (bool, error) {
+	unlock, err := s.lock(false)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	data, err := s.read()
+	if err != nil {
+		return false, err
+	}
+	raw, ok := data[key]
+	if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return false, errors.Wrap(err, "failed to decode state of key '"+key+"'")
+	}
+	return true, nil
+}
+
+// Save encodes v as JSON and saves it under the key.
+func (s *FileStore) Save(key string, v interface{}) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode state of key '"+key+"'")
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return errors.Wrap(err, "failed to create state directory")
+	}
+
+	unlock, err := s.lock(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	data, err := s.read()
+	if err != nil {
+		return err
+	}
+	data[key] = raw
+	return s.write(data)
+}
+
+// Delete removes the data that was saved under the key.
+func (s *FileStore) Delete(key string) error {
+	unlock, err := s.lock(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	data, err := s.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := data[key]; !ok {
+		return nil
+	}
+	delete(data, key)
+	return s.write(data)
+}
+
+// lock locks the lock file of the store and returns a function that releases the lock.
+func (s *FileStore) lock(exclusive bool) (func(), error) {
+	file, err := os.OpenFile(s.path+".lock", os.O_CREATE|os.O_RDWR, 0644)
+	if os.IsNotExist(err) && !exclusive {
+		// nothing was saved yet, so there is nothing to lock
+		return func() {}, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open lock file")
+	}
+	if err := lockFile(file, exclusive); err != nil {
+		_ = file.Close()
+		return nil, errors.Wrap(err, "failed to lock state file")
+	}
+	return func() {
+		_ = unlockFile(file)
+		_ = file.Close()
+	}, nil
+}
+
+// read reads the JSON file. If it does not exist, an empty map is returned.
+func (s *FileStore) read() (map[string]json.RawMessage, error) {
+	data := make(map[string]json.RawMessage)
+	content, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return data, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read state file")
+	}
+	if err := json.Unmarshal(content, &data); err != nil {
+		return nil, errors.Wrap(err, "failed to decode state file")
+	}
+	return data, nil
+}
+
+// write replaces the JSON file atomically by writing a temporary file and renaming it.
+func (s *FileStore) write(data map[string]json.RawMessage) error {
+	content, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode state file")
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp*")
+	if err != nil {
+		return errors.Wrap(err, "failed to create temporary state file")
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(content); err != nil {
+		_ = tmp.Close()
+		return errors.Wrap(err, "failed to write temporary state file")
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return errors.Wrap(err, "failed to sync temporary state file")
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(err, "failed to close temporary state file")
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return errors.Wrap(err, "failed to replace state file")
+	}
+	return nil
+}
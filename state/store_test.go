@@ -0,0 +1,92 @@
+package state
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+type sample struct {
+	Value     float64 `json:"value"`
+	Timestamp int64   `json:"timestamp"`
+}
+
+func TestNewFileStore(t *testing.T) {
+	assert.Equal(t, "/var/lib/monitoringplugin/check_interfaces.json", NewFileStore("check_interfaces").Path())
+}
+
+func TestFileStore(t *testing.T) {
+	store := NewFileStoreAt(filepath.Join(t.TempDir(), "sub", "check.json"))
+
+	var s sample
+	found, err := store.Load("eth0", &s)
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	assert.NoError(t, store.Save("eth0", sample{Value: 1.5, Timestamp: 100}))
+	assert.NoError(t, store.Save("eth1", sample{Value: 3, Timestamp: 200}))
+	found, err = store.Load("eth0", &s)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, sample{Value: 1.5, Timestamp: 100}, s)
+
+	// a new store with the same file sees the data of previous invocations
+	store = NewFileStoreAt(store.Path())
+	found, err = store.Load("eth1", &s)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, sample{Value: 3, Timestamp: 200}, s)
+
+	assert.NoError(t, store.Delete("eth1"))
+	assert.NoError(t, store.Delete("eth1"))
+	found, err = store.Load("eth1", &s)
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	// no temporary files are left behind
+	entries, err := os.ReadDir(filepath.Dir(store.Path()))
+	assert.NoError(t, err)
+	for _, entry := range entries {
+		assert.Contains(t, []string{"check.json", "check.json.lock"}, entry.Name())
+	}
+}
+
+func TestFileStore_Concurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "check.json")
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, NewFileStoreAt(path).Save("key"+strconv.Itoa(i), i))
+		}(i)
+	}
+	wg.Wait()
+
+	store := NewFileStoreAt(path)
+	for i := 0; i < 20; i++ {
+		var v int
+		found, err := store.Load("key"+strconv.Itoa(i), &v)
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, i, v)
+	}
+}
+
+func TestFileStore_Errors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "check.json")
+	assert.NoError(t, os.WriteFile(path, []byte("no json"), 0644))
+	store := NewFileStoreAt(path)
+	var v int
+	_, err := store.Load("key", &v)
+	assert.Error(t, err)
+	assert.Error(t, store.Save("key", 1))
+
+	assert.NoError(t, os.WriteFile(path, []byte(`{"key": "text"}`), 0644))
+	_, err = store.Load("key", &v)
+	assert.Error(t, err)
+	assert.Error(t, store.Save("key", func() {}))
+}
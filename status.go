@@ -0,0 +1,109 @@
+package monitoringplugin
+
+import (
+	"encoding/json"
+	"github.com/pkg/errors"
+	"strconv"
+	"strings"
+)
+
+// Status is the status of a check plugin. It is also used as the exit code of the check plugin.
+type Status int
+
+const (
+	// OK check plugin status = OK
+	OK Status = 0
+	// WARNING check plugin status = WARNING
+	WARNING Status = 1
+	// CRITICAL check plugin status = CRITICAL
+	CRITICAL Status = 2
+	// UNKNOWN check plugin status = UNKNOWN
+	UNKNOWN Status = 3
+	// DEPENDENT check plugin status = DEPENDENT
+	// Only used if it was activated with Response.AllowDependentStatus(bool), otherwise it is mapped to UNKNOWN.
+	DEPENDENT Status = 4
+)
+
+// statusesBySeverity contains all status codes, starting with the most severe one.
+var statusesBySeverity = []Status{CRITICAL, DEPENDENT, UNKNOWN, WARNING, OK}
+
+// String returns the text representation of the status (see StatusCode2Text(Status)).
+func (s Status) String() string {
+	return StatusCode2Text(s)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (s Status) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface. It accepts status texts and numeric status codes.
+func (s *Status) UnmarshalText(text []byte) error {
+	status, err := ParseStatusStrict(string(text))
+	if err != nil {
+		return err
+	}
+	*s = status
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface. The status is marshaled as its numeric code.
+func (s Status) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Itoa(int(s))), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It accepts numeric status codes and status texts.
+func (s *Status) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		return s.UnmarshalText([]byte(text))
+	}
+	var code int
+	if err := json.Unmarshal(data, &code); err != nil {
+		return errors.Wrap(err, "status must be a number or a string")
+	}
+	*s = Status(code)
+	return nil
+}
+
+// MarshalYAML implements the yaml.Marshaler interface. Like in JSON, the status is marshaled as its numeric code.
+func (s Status) MarshalYAML() (interface{}, error) {
+	return int(s), nil
+}
+
+/*
+ParseStatus returns the status for a status text (case insensitive).
+Unlike String2StatusCode(string), it returns an error if the text is not a valid status.
+*/
+func ParseStatus(s string) (Status, error) {
+	switch {
+	case strings.EqualFold("OK", s):
+		return OK, nil
+	case strings.EqualFold("WARNING", s):
+		return WARNING, nil
+	case strings.EqualFold("CRITICAL", s):
+		return CRITICAL, nil
+	case strings.EqualFold("UNKNOWN", s):
+		return UNKNOWN, nil
+	case strings.EqualFold("DEPENDENT", s):
+		return DEPENDENT, nil
+	default:
+		return UNKNOWN, errors.New("invalid status '" + s + "'")
+	}
+}
+
+/*
+ParseStatusStrict returns the status for a status text (case insensitive) or a numeric status code ("0" - "4").
+Unlike String2StatusCode(string), which maps everything unknown to UNKNOWN, it returns an error for any other input.
+This is useful for importing results of external checks where garbage input has to be detected.
+*/
+func ParseStatusStrict(s string) (Status, error) {
+	if code, err := strconv.Atoi(s); err == nil {
+		status := Status(code)
+		if status < OK || status > DEPENDENT {
+			return UNKNOWN, errors.New("invalid status code '" + s + "'")
+		}
+		return status, nil
+	}
+	return ParseStatus(s)
+}
@@ -0,0 +1,103 @@
+package monitoringplugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+	"testing"
+)
+
+func TestStatus_String(t *testing.T) {
+	assert.Equal(t, "OK", OK.String())
+	assert.Equal(t, "WARNING", WARNING.String())
+	assert.Equal(t, "CRITICAL", CRITICAL.String())
+	assert.Equal(t, "UNKNOWN", UNKNOWN.String())
+	assert.Equal(t, "DEPENDENT", DEPENDENT.String())
+	assert.Equal(t, "UNKNOWN", Status(7).String())
+	assert.Equal(t, "CRITICAL", fmt.Sprint(CRITICAL))
+}
+
+func TestParseStatus(t *testing.T) {
+	status, err := ParseStatus("warning")
+	assert.NoError(t, err)
+	assert.Equal(t, WARNING, status)
+	status, err = ParseStatus("Critical")
+	assert.NoError(t, err)
+	assert.Equal(t, CRITICAL, status)
+	status, err = ParseStatus("dependent")
+	assert.NoError(t, err)
+	assert.Equal(t, DEPENDENT, status)
+	_, err = ParseStatus("critcal")
+	assert.Error(t, err)
+}
+
+func TestStatus_MarshalText(t *testing.T) {
+	b, err := CRITICAL.MarshalText()
+	assert.NoError(t, err)
+	assert.Equal(t, "CRITICAL", string(b))
+
+	var status Status
+	assert.NoError(t, status.UnmarshalText([]byte("warning")))
+	assert.Equal(t, WARNING, status)
+	assert.Error(t, status.UnmarshalText([]byte("WARN")))
+}
+
+func TestOutputMessage_MarshalJSON(t *testing.T) {
+	b, err := json.Marshal(OutputMessage{Status: CRITICAL, Message: "test"})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"status":2,"status_text":"CRITICAL","message":"test"}`, string(b))
+
+	var message OutputMessage
+	assert.NoError(t, json.Unmarshal(b, &message))
+	assert.Equal(t, OutputMessage{Status: CRITICAL, Message: "test"}, message)
+	assert.NoError(t, json.Unmarshal([]byte(`{"status":"WARNING","message":"test"}`), &message))
+	assert.Equal(t, OutputMessage{Status: WARNING, Message: "test"}, message)
+	assert.Error(t, json.Unmarshal([]byte(`{"status":"WARN"}`), &message))
+	assert.Error(t, json.Unmarshal([]byte(`{"status":true}`), &message))
+}
+
+func TestOutputMessage_MarshalYAML(t *testing.T) {
+	b, err := yaml.Marshal(OutputMessage{Status: WARNING, Message: "test"})
+	assert.NoError(t, err)
+	assert.Equal(t, "status: 1\nstatus_text: WARNING\nmessage: test\n", string(b))
+}
+
+func TestStatus_MarshalYAML(t *testing.T) {
+	type result struct {
+		Status Status `yaml:"status" json:"status"`
+	}
+	b, err := yaml.Marshal(result{CRITICAL})
+	assert.NoError(t, err)
+	assert.Equal(t, "status: 2\n", string(b))
+	j, err := json.Marshal(result{CRITICAL})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"status":2}`, string(j))
+
+	var r result
+	assert.NoError(t, yaml.Unmarshal(b, &r))
+	assert.Equal(t, CRITICAL, r.Status)
+	assert.NoError(t, yaml.Unmarshal([]byte("status: warning\n"), &r))
+	assert.Equal(t, WARNING, r.Status)
+	assert.Error(t, yaml.Unmarshal([]byte("status: WARN\n"), &r))
+}
+
+func TestParseStatusStrict(t *testing.T) {
+	status, err := ParseStatusStrict("2")
+	assert.NoError(t, err)
+	assert.Equal(t, CRITICAL, status)
+	status, err = ParseStatusStrict("ok")
+	assert.NoError(t, err)
+	assert.Equal(t, OK, status)
+	status, err = ParseStatusStrict("4")
+	assert.NoError(t, err)
+	assert.Equal(t, DEPENDENT, status)
+	_, err = ParseStatusStrict("5")
+	assert.Error(t, err)
+	_, err = ParseStatusStrict("-1")
+	assert.Error(t, err)
+	_, err = ParseStatusStrict("warn")
+	assert.Error(t, err)
+	_, err = ParseStatusStrict("")
+	assert.Error(t, err)
+}
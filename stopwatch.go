@@ -0,0 +1,56 @@
+package monitoringplugin
+
+import (
+	"time"
+)
+
+// Stopwatch measures the elapsed time of an operation and adds it as a PerformanceDataPoint to a Response.
+type Stopwatch struct {
+	response   *Response
+	metric     string
+	label      string
+	start      time.Time
+	thresholds Thresholds
+}
+
+/*
+StartStopwatch starts a new Stopwatch. When Stop() is called, a PerformanceDataPoint with the elapsed time in seconds
+(e.g. 'time'=0.123s;;;0) is added to the response, like the core HTTP/TCP plugins do.
+Usage:
+	sw := Response.StartStopwatch("time")
+	defer sw.Stop()
+*/
+func (r *Response) StartStopwatch(metric string) *Stopwatch {
+	return &Stopwatch{
+		response: r,
+		metric:   metric,
+		start:    time.Now(),
+	}
+}
+
+// SetThresholds sets the thresholds (in seconds) the elapsed time is checked against when the stopwatch is stopped.
+func (s *Stopwatch) SetThresholds(thresholds Thresholds) *Stopwatch {
+	s.thresholds = thresholds
+	return s
+}
+
+// SetLabel sets the label of the PerformanceDataPoint that is added when the stopwatch is stopped.
+func (s *Stopwatch) SetLabel(label string) *Stopwatch {
+	s.label = label
+	return s
+}
+
+// Elapsed returns the time that has elapsed since the stopwatch was started.
+func (s *Stopwatch) Elapsed() time.Duration {
+	return time.Since(s.start)
+}
+
+// Stop stops the stopwatch and adds the elapsed time as a PerformanceDataPoint to the response.
+func (s *Stopwatch) Stop() error {
+	point := NewPerformanceDataPoint(s.metric, s.Elapsed().Seconds()).
+		SetUnit("s").
+		SetLabel(s.label).
+		SetMin(0).
+		SetThresholds(s.thresholds)
+	return s.response.AddPerformanceDataPoint(point)
+}
@@ -0,0 +1,25 @@
+package monitoringplugin
+
+import (
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestResponse_StartStopwatch(t *testing.T) {
+	r := NewResponse("")
+	sw := r.StartStopwatch("time")
+	time.Sleep(10 * time.Millisecond)
+	assert.NoError(t, sw.Stop())
+	assert.Equal(t, OK, r.statusCode)
+	point := r.performanceData[performanceDataPointKey{Metric: "time"}]
+	assert.Regexp(t, regexp.MustCompile(`^'time'=0\.[0-9]+s;;;0;$`), string(point.output(false)))
+
+	sw = r.StartStopwatch("time").SetLabel("slow").SetThresholds(NewThresholds(nil, 0.001, nil, 10))
+	time.Sleep(10 * time.Millisecond)
+	assert.NoError(t, sw.Stop())
+	assert.Equal(t, WARNING, r.statusCode)
+
+	assert.Error(t, r.StartStopwatch("time").Stop())
+}
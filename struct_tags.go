@@ -0,0 +1,152 @@
+package monitoringplugin
+
+import (
+	"github.com/pkg/errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// perfdataTag is the name of the struct tag that is read by Response.AddMetricsFromStruct.
+const perfdataTag = "perfdata"
+
+/*
+AddMetricsFromStruct creates performance data points from the numeric fields of a struct (or a pointer to a struct)
+that have a perfdata struct tag and adds them like AddPerformanceDataPoints(...*PerformanceDataPoint). The tag contains
+the metric, followed by optional comma separated key=value pairs: unit, label, min, max, warn and crit. Thresholds are
+upper bounds ("warn=80") or ranges ("warn=10:80"). If the metric is empty, the name of the field is used. Untagged
+nested structs are searched for tagged fields, fields tagged with "-" are skipped. time.Duration fields are converted
+to seconds with the unit 's'. If a tag is invalid, no performance data point is added.
+Usage:
+	type diskStatus struct {
+		Used  float64 `perfdata:"used,unit=%,warn=80,crit=90,min=0,max=100"`
+		Inodes int    `perfdata:"inodes,min=0"`
+	}
+	err := Response.AddMetricsFromStruct(status)
+*/
+func (r *Response) AddMetricsFromStruct(v interface{}) error {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return errors.New("given value is nil")
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return errors.Errorf("given value is not a struct, but %s", value.Kind())
+	}
+	points, err := structDataPoints(value)
+	if err != nil {
+		return errors.Wrap(err, "failed to read perfdata struct tags")
+	}
+	return r.AddPerformanceDataPoints(points...)
+}
+
+// structDataPoints returns the performance data points of all tagged fields of the struct.
+func structDataPoints(value reflect.Value) ([]*PerformanceDataPoint, error) {
+	var points []*PerformanceDataPoint
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		fieldValue := value.Field(i)
+		tag, ok := field.Tag.Lookup(perfdataTag)
+		if !ok {
+			for fieldValue.Kind() == reflect.Ptr && !fieldValue.IsNil() {
+				fieldValue = fieldValue.Elem()
+			}
+			if fieldValue.Kind() == reflect.Struct {
+				nested, err := structDataPoints(fieldValue)
+				if err != nil {
+					return nil, err
+				}
+				points = append(points, nested...)
+			}
+			continue
+		}
+		if tag == "-" {
+			continue
+		}
+		point, err := structFieldDataPoint(field, fieldValue, tag)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid field %s", field.Name)
+		}
+		points = append(points, point)
+	}
+	return points, nil
+}
+
+// structFieldDataPoint creates a performance data point from a struct field and its perfdata tag.
+func structFieldDataPoint(field reflect.StructField, value reflect.Value, tag string) (*PerformanceDataPoint, error) {
+	options := strings.Split(tag, ",")
+	metric := strings.TrimSpace(options[0])
+	if metric == "" {
+		metric = field.Name
+	}
+
+	point := NewPerformanceDataPoint(metric, nil)
+	switch {
+	case value.Type() == reflect.TypeOf(time.Duration(0)):
+		point.Value = time.Duration(value.Int()).Seconds()
+		point.Unit = "s"
+	case value.CanInt():
+		point.Value = value.Int()
+	case value.CanUint():
+		point.Value = value.Uint()
+	case value.CanFloat():
+		point.Value = value.Float()
+	default:
+		return nil, errors.Errorf("unsupported type %s", value.Type())
+	}
+
+	for _, option := range options[1:] {
+		key, val, ok := strings.Cut(strings.TrimSpace(option), "=")
+		if !ok {
+			return nil, errors.Errorf("invalid tag option '%s'", option)
+		}
+		switch key {
+		case "unit":
+			point.SetUnit(val)
+		case "label":
+			point.SetLabel(val)
+		case "min":
+			point.SetMin(tagNumber(val))
+		case "max":
+			point.SetMax(tagNumber(val))
+		case "warn":
+			point.Thresholds.WarningMin, point.Thresholds.WarningMax = tagRange(val)
+		case "crit":
+			point.Thresholds.CriticalMin, point.Thresholds.CriticalMax = tagRange(val)
+		default:
+			return nil, errors.Errorf("unknown tag option '%s'", key)
+		}
+	}
+	return point, nil
+}
+
+// tagRange parses a threshold of a perfdata tag, which is either an upper bound ("80") or a range ("10:80") where
+// both bounds are optional.
+func tagRange(s string) (interface{}, interface{}) {
+	lower, upper, ok := strings.Cut(s, ":")
+	if !ok {
+		return nil, tagNumber(s)
+	}
+	return tagNumber(lower), tagNumber(upper)
+}
+
+// tagNumber returns nil for an empty number, otherwise the number as int64 or float64. If it is not a number, it is
+// returned as string, which is reported when the performance data point is validated.
+func tagNumber(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+	return s
+}
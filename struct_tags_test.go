@@ -0,0 +1,46 @@
+package monitoringplugin
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type testDiskStatus struct {
+	Used    float64       `perfdata:"used,unit=%,warn=80,crit=90,min=0,max=100"`
+	Inodes  uint32        `perfdata:",min=0,label=root"`
+	Latency time.Duration `perfdata:"latency,warn=0.5:1.5"`
+	Name    string
+	Ignored int `perfdata:"-"`
+	Nested  struct {
+		Files int `perfdata:"files"`
+	}
+	hidden int `perfdata:"hidden"`
+}
+
+func TestResponse_AddMetricsFromStruct(t *testing.T) {
+	status := testDiskStatus{
+		Used:    85.5,
+		Inodes:  1234,
+		Latency: 250 * time.Millisecond,
+		Name:    "root",
+		Ignored: 1,
+		hidden:  1,
+	}
+	status.Nested.Files = 42
+
+	r := NewResponse("checked")
+	assert.NoError(t, r.AddMetricsFromStruct(&status))
+	assert.Equal(t, "'used'=85.5%;~:80;~:90;0;100 'Inodes_root'=1234;;;0; 'latency'=0.25s;0.5:1.5;;; 'files'=42",
+		r.performanceDataOutput())
+	assert.Equal(t, WARNING, r.GetStatusCode())
+
+	assert.Error(t, r.AddMetricsFromStruct(1))
+	assert.Error(t, r.AddMetricsFromStruct((*testDiskStatus)(nil)))
+	assert.Error(t, r.AddMetricsFromStruct(struct {
+		Name string `perfdata:"name"`
+	}{}))
+	assert.Error(t, r.AddMetricsFromStruct(struct {
+		Value int `perfdata:"value,color=red"`
+	}{}))
+}
@@ -0,0 +1,218 @@
+package monitoringplugin
+
+import (
+	"bytes"
+	"github.com/pkg/errors"
+	"strings"
+)
+
+// SubCheckPolicy specifies how the status of the sub-checks is aggregated into the status of the Response.
+type SubCheckPolicy int
+
+const (
+	// SubCheckPolicyWorst uses the worst status of all sub-checks.
+	SubCheckPolicyWorst SubCheckPolicy = iota + 1
+	// SubCheckPolicyBest uses the best status of all sub-checks.
+	SubCheckPolicyBest
+	// SubCheckPolicyQuorum uses OK if at least quorum sub-checks are OK, otherwise the worst status of all sub-checks.
+	SubCheckPolicyQuorum
+)
+
+// SubCheck is a part of a check with its own status, messages and performance data.
+// It is attached to a Response (or another SubCheck) with AddSubCheck(string, *SubCheck).
+type SubCheck struct {
+	statusCode      Status
+	outputMessages  []OutputMessage
+	performanceData performanceData
+	subChecks       []namedSubCheck
+	sequence        int
+}
+
+type namedSubCheck struct {
+	name     string
+	subCheck *SubCheck
+}
+
+// NewSubCheck creates a new SubCheck with status OK.
+func NewSubCheck() *SubCheck {
+	return &SubCheck{
+		statusCode:      OK,
+		performanceData: make(performanceData),
+	}
+}
+
+// UpdateStatus updates the status of the SubCheck and adds a message (see Response.UpdateStatus(Status, string)).
+func (s *SubCheck) UpdateStatus(statusCode Status, statusMessage string) {
+	if isWorseStatus(statusCode, s.statusCode) {
+		s.statusCode = statusCode
+	}
+	if statusMessage != "" {
+		s.outputMessages = append(s.outputMessages, OutputMessage{Status: statusCode, Message: statusMessage})
+	}
+}
+
+// GetStatusCode returns the current status code of the SubCheck, including the status of its own sub-checks.
+func (s *SubCheck) GetStatusCode() Status {
+	status := s.statusCode
+	for _, sub := range s.subChecks {
+		if subStatus := sub.subCheck.GetStatusCode(); isWorseStatus(subStatus, status) {
+			status = subStatus
+		}
+	}
+	return status
+}
+
+// AddPerformanceDataPoint adds a PerformanceDataPoint to the SubCheck and checks its thresholds
+// (see Response.AddPerformanceDataPoint(*PerformanceDataPoint)).
+func (s *SubCheck) AddPerformanceDataPoint(point *PerformanceDataPoint) error {
+	point.sequence = s.sequence
+	s.sequence++
+	err := s.performanceData.add(point)
+	if err != nil {
+		return errors.Wrap(err, "failed to add performance data point")
+	}
+
+	if !point.Thresholds.IsEmpty() {
+		res, err := point.Thresholds.CheckValue(point.Value)
+		if err != nil {
+			return errors.Wrap(err, "failed to check thresholds")
+		}
+		if res != OK {
+			s.UpdateStatus(res, point.name()+" is outside of "+StatusCode2Text(res)+" threshold")
+		}
+	}
+	return nil
+}
+
+// AddSubCheck attaches a nested SubCheck. Its status is aggregated using the worst status.
+func (s *SubCheck) AddSubCheck(name string, subCheck *SubCheck) {
+	s.subChecks = append(s.subChecks, namedSubCheck{name, subCheck})
+}
+
+/*
+AddSubCheck attaches a SubCheck to the Response. The status of all sub-checks is aggregated into the status of the
+Response using the sub-check policy (see SetSubCheckPolicy). Sub-checks are rendered as indented sections in the long
+output, their performance data is prefixed with the name of the sub-check (e.g. 'disk1::usage').
+Usage:
+	sub := NewSubCheck()
+	sub.UpdateStatus(WARNING, "disk is almost full")
+	Response.AddSubCheck("disk1", sub)
+*/
+func (r *Response) AddSubCheck(name string, subCheck *SubCheck) {
+	defer r.beginModification()()
+	if err := r.checkFinalized(); err != nil {
+		r.warn("sub-check added after finalize ignored", "name", name, "error", err)
+		return
+	}
+	r.subChecks = append(r.subChecks, namedSubCheck{name, subCheck})
+}
+
+// cloneSubChecks returns a deep copy of the sub-checks.
+func cloneSubChecks(subChecks []namedSubCheck) []namedSubCheck {
+	var res []namedSubCheck
+	for _, sub := range subChecks {
+		clone := *sub.subCheck
+		clone.outputMessages = append([]OutputMessage(nil), sub.subCheck.outputMessages...)
+		clone.performanceData = make(performanceData, len(sub.subCheck.performanceData))
+		for key, point := range sub.subCheck.performanceData {
+			clone.performanceData[key] = point
+		}
+		clone.subChecks = cloneSubChecks(sub.subCheck.subChecks)
+		res = append(res, namedSubCheck{sub.name, &clone})
+	}
+	return res
+}
+
+// SetSubCheckPolicy sets how the status of the sub-checks is aggregated. Default is SubCheckPolicyWorst.
+// quorum is only necessary if SubCheckPolicyQuorum is set.
+func (r *Response) SetSubCheckPolicy(policy SubCheckPolicy, quorum int) error {
+	switch policy {
+	case SubCheckPolicyQuorum:
+		if quorum < 1 {
+			return errors.New("quorum must be greater than 0")
+		}
+		r.subCheckQuorum = quorum
+		fallthrough
+	case SubCheckPolicyWorst, SubCheckPolicyBest:
+		r.subCheckPolicy = policy
+	default:
+		return errors.New("unknown policy")
+	}
+	return nil
+}
+
+// aggregateSubChecks updates the status of the response with the aggregated status of all sub-checks.
+func (r *Response) aggregateSubChecks() {
+	if len(r.subChecks) == 0 {
+		return
+	}
+	worst, best := OK, CRITICAL
+	okCount := 0
+	for _, sub := range r.subChecks {
+		status := sub.subCheck.GetStatusCode()
+		if isWorseStatus(status, worst) {
+			worst = status
+		}
+		if isWorseStatus(best, status) {
+			best = status
+		}
+		if status == OK {
+			okCount++
+		}
+	}
+
+	switch r.subCheckPolicy {
+	case SubCheckPolicyBest:
+		r.updateStatus(OutputMessage{Status: best})
+	case SubCheckPolicyQuorum:
+		if okCount < r.subCheckQuorum {
+			r.updateStatus(OutputMessage{Status: worst})
+		}
+	default: // SubCheckPolicyWorst
+		r.updateStatus(OutputMessage{Status: worst})
+	}
+}
+
+// validateSubChecks handles invalid characters in the messages of all sub-checks (see validateLongOutput()).
+func (r *Response) validateSubChecks(subChecks []namedSubCheck) {
+	for _, sub := range subChecks {
+		var messages []OutputMessage
+		for _, message := range sub.subCheck.outputMessages {
+			if message.Message = r.sanitizeMessage(message); message.Message != "" {
+				messages = append(messages, message)
+			}
+		}
+		sub.subCheck.outputMessages = messages
+		r.validateSubChecks(sub.subCheck.subChecks)
+	}
+}
+
+// writeSubChecks writes all sub-checks as sections that are indented with the indentation prefix, e.g.:
+//	\_ [WARNING] disk1
+//	    disk is almost full
+func (r *Response) writeSubChecks(buffer *bytes.Buffer, subChecks []namedSubCheck, depth int) {
+	indent := strings.Repeat(r.indent, depth)
+	for _, sub := range subChecks {
+		buffer.WriteByte('\n')
+		buffer.WriteString(indent + "\\_ [" + r.statusText(sub.subCheck.GetStatusCode()) + "] " + sub.name)
+		for _, message := range sub.subCheck.outputMessages {
+			buffer.WriteByte('\n')
+			buffer.WriteString(indent + r.indent + r.indentLines(message.Message, indent+r.indent+r.indent))
+		}
+		r.writeSubChecks(buffer, sub.subCheck.subChecks, depth+1)
+	}
+}
+
+// subCheckPerformanceData returns the performance data points of all sub-checks with prefixed metrics.
+func subCheckPerformanceData(subChecks []namedSubCheck, prefix string) []PerformanceDataPoint {
+	var points []PerformanceDataPoint
+	for _, sub := range subChecks {
+		name := prefix + sub.name + "::"
+		for _, point := range sub.subCheck.performanceData.getInfo() {
+			point.Metric = name + point.Metric
+			points = append(points, point)
+		}
+		points = append(points, subCheckPerformanceData(sub.subCheck.subChecks, name)...)
+	}
+	return points
+}
@@ -0,0 +1,111 @@
+package monitoringplugin
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestResponse_AddSubCheck(t *testing.T) {
+	r := NewResponse("checked")
+	disk1 := NewSubCheck()
+	assert.NoError(t, disk1.AddPerformanceDataPoint(NewPerformanceDataPoint("usage", 85).SetUnit("%").
+		SetThresholds(NewThresholds(nil, 80, nil, 90))))
+	disk2 := NewSubCheck()
+	disk2.UpdateStatus(OK, "disk|2 is fine")
+	partition := NewSubCheck()
+	partition.UpdateStatus(CRITICAL, "partition is full")
+	disk2.AddSubCheck("partition1", partition)
+	r.AddSubCheck("disk1", disk1)
+	r.AddSubCheck("disk2", disk2)
+
+	assert.Equal(t, CRITICAL, disk2.GetStatusCode())
+	res := r.GetInfo()
+	assert.Equal(t, CRITICAL, res.StatusCode)
+	assert.Equal(t, "CRITICAL\n\\_ [WARNING] disk1\n    usage is outside of WARNING threshold\n\\_ [CRITICAL] disk2\n"+
+		"    disk2 is fine\n    \\_ [CRITICAL] partition1\n        partition is full | 'disk1::usage'=85%;~:80;~:90;;", res.RawOutput)
+}
+
+func TestResponse_SetIndentation_SubChecks(t *testing.T) {
+	r := NewResponse("checked")
+	r.SetIndentation("..")
+	r.IndentContinuationLines(true)
+	disk := NewSubCheck()
+	partition := NewSubCheck()
+	partition.UpdateStatus(CRITICAL, "partition is full\nlargest file: /var/log/syslog")
+	disk.AddSubCheck("partition1", partition)
+	r.AddSubCheck("disk1", disk)
+	assert.Equal(t, "CRITICAL\n\\_ [CRITICAL] disk1\n..\\_ [CRITICAL] partition1\n....partition is full\n"+
+		"......largest file: /var/log/syslog", r.String())
+}
+
+func TestResponse_SetSubCheckPolicy(t *testing.T) {
+	newResponse := func() *Response {
+		r := NewResponse("checked")
+		for _, status := range []Status{OK, OK, WARNING} {
+			sub := NewSubCheck()
+			sub.UpdateStatus(status, "")
+			r.AddSubCheck("sub", sub)
+		}
+		return r
+	}
+
+	r := newResponse()
+	assert.Error(t, r.SetSubCheckPolicy(SubCheckPolicyQuorum, 0))
+	assert.Error(t, r.SetSubCheckPolicy(SubCheckPolicy(42), 0))
+	assert.Equal(t, WARNING, r.GetInfo().StatusCode)
+
+	r = newResponse()
+	assert.NoError(t, r.SetSubCheckPolicy(SubCheckPolicyBest, 0))
+	assert.Equal(t, OK, r.GetInfo().StatusCode)
+
+	r = newResponse()
+	assert.NoError(t, r.SetSubCheckPolicy(SubCheckPolicyQuorum, 2))
+	assert.Equal(t, OK, r.GetInfo().StatusCode)
+
+	r = newResponse()
+	assert.NoError(t, r.SetSubCheckPolicy(SubCheckPolicyQuorum, 3))
+	assert.Equal(t, WARNING, r.GetInfo().StatusCode)
+}
+
+func TestResponse_AddSubCheck_RepeatedOutput(t *testing.T) {
+	r := NewResponse("checked")
+	sub := NewSubCheck()
+	sub.UpdateStatus(WARNING, "disk is almost full")
+	r.AddSubCheck("disk1", sub)
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, "WARNING\n\\_ [WARNING] disk1\n    disk is almost full", r.String())
+	}
+	assert.Equal(t, []Status{WARNING}, r.silentStatusUpdates)
+}
+
+func TestResponse_AddSubCheck_EmptyMessages(t *testing.T) {
+	r := NewResponse("checked")
+	r.UpdateStatus(CRITICAL, "|")
+	sub := NewSubCheck()
+	sub.UpdateStatus(WARNING, "disk is almost full")
+	r.AddSubCheck("disk1", sub)
+	assert.Equal(t, "CRITICAL\n\\_ [WARNING] disk1\n    disk is almost full", r.String())
+
+	r = NewResponse("checked")
+	r.UpdateStatus(CRITICAL, "|")
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("load", 1)))
+	assert.Equal(t, "CRITICAL | 'load'=1", r.String())
+}
+
+func TestResponse_AddSubCheck_PerformanceDataOrder(t *testing.T) {
+	metrics := []string{"zeta", "alpha", "mu", "beta", "omega", "gamma", "kappa", "delta"}
+	var expected []string
+	for _, metric := range metrics {
+		expected = append(expected, "'disk1::"+metric+"'=1")
+	}
+	for i := 0; i < 20; i++ {
+		r := NewResponse("checked")
+		sub := NewSubCheck()
+		for _, metric := range metrics {
+			assert.NoError(t, sub.AddPerformanceDataPoint(NewPerformanceDataPoint(metric, 1)))
+		}
+		r.AddSubCheck("disk1", sub)
+		assert.Equal(t, "OK: checked\n\\_ [OK] disk1 | "+strings.Join(expected, " "), r.String())
+	}
+}
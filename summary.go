@@ -0,0 +1,42 @@
+package monitoringplugin
+
+/*
+SummaryStrategy composes the summary that is displayed on the first line of the output (see SetSummary(string)),
+which is what most dashboards display. If it returns an empty string, no summary is displayed.
+*/
+type SummaryStrategy func(r *Response) string
+
+// SummaryWorstMessage uses the most severe output message as summary.
+func SummaryWorstMessage(r *Response) string {
+	message, ok := r.WorstMessage()
+	if !ok {
+		return ""
+	}
+	return message.text()
+}
+
+// SummaryFirstMessage uses the first output message as summary.
+func SummaryFirstMessage(r *Response) string {
+	if len(r.outputMessages) == 0 {
+		return ""
+	}
+	return r.outputMessages[0].text()
+}
+
+// SummaryCountsOnly uses the number of output messages per status as summary, e.g. "3 critical, 2 warning, 10 ok".
+func SummaryCountsOnly(r *Response) string {
+	return r.statusCounts()
+}
+
+/*
+SetSummaryStrategy sets the strategy that composes the summary if no summary was set with SetSummary(string).
+All output messages are still displayed in the following lines.
+Example:
+	Response.SetSummaryStrategy(SummaryWorstMessage)
+	//CRITICAL: disk /home is 99% full | performanceData
+	//disk /home is 99% full
+	//disk /var is 85% full
+*/
+func (r *Response) SetSummaryStrategy(strategy SummaryStrategy) {
+	r.summaryStrategy = strategy
+}
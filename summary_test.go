@@ -0,0 +1,47 @@
+package monitoringplugin
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func newSummaryTestResponse() *Response {
+	r := NewResponse("everything checked!")
+	r.UpdateStatus(WARNING, "disk /var is 85% full")
+	r.UpdateStatus(CRITICAL, "disk /home is 99% full")
+	r.UpdateStatus(OK, "disk /tmp is fine")
+	return r
+}
+
+func TestResponse_SetSummaryStrategy(t *testing.T) {
+	r := newSummaryTestResponse()
+	r.SetSummaryStrategy(SummaryWorstMessage)
+	assert.Equal(t, "CRITICAL: disk /home is 99% full\ndisk /home is 99% full\ndisk /var is 85% full\ndisk /tmp is fine", r.String())
+
+	r = newSummaryTestResponse()
+	r.SortOutputMessagesByStatus(false)
+	r.SetSummaryStrategy(SummaryFirstMessage)
+	assert.Equal(t, "CRITICAL: disk /var is 85% full\ndisk /var is 85% full\ndisk /home is 99% full\ndisk /tmp is fine", r.String())
+
+	r = newSummaryTestResponse()
+	r.SetSummaryStrategy(SummaryCountsOnly)
+	r.SetQuiet(true)
+	assert.Equal(t, "CRITICAL: 1 critical, 1 warning, 1 ok", r.String())
+
+	r = newSummaryTestResponse()
+	r.SetSummaryStrategy(func(r *Response) string {
+		return strings.ToUpper(r.Messages(WARNING)[0].Message)
+	})
+	r.SetSummary("explicit summary")
+	r.SetQuiet(true)
+	assert.Equal(t, "CRITICAL: explicit summary", r.String())
+	r.SetSummary("")
+	assert.Equal(t, "CRITICAL: DISK /VAR IS 85% FULL", r.String())
+}
+
+func TestResponse_SetSummaryStrategy_NoMessages(t *testing.T) {
+	r := NewResponse("everything checked!")
+	r.SetSummaryStrategy(SummaryWorstMessage)
+	assert.Equal(t, "OK: everything checked!", r.String())
+}
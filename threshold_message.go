@@ -0,0 +1,37 @@
+package monitoringplugin
+
+import (
+	"bytes"
+	"text/template"
+)
+
+/*
+SetThresholdMessageTemplate sets a template that is used to render the output messages of threshold violations (see
+CheckThresholds and AddPerformanceDataPoint). The template receives the ViolationInfo of the violation, its ValueText
+and BoundText methods format the numbers like the performance data. If the template can not be executed, the default
+message "<name> is outside of <status> threshold" is used. If tmpl is nil, the default message is used, which is the
+default.
+Example:
+	tmpl := template.Must(template.New("threshold").Parse(
+		"{{.Name}} is {{.ValueText}}{{.Unit}}, {{.Direction}} {{.StatusText}} threshold {{.BoundText}}{{.Unit}}"))
+	Response.SetThresholdMessageTemplate(tmpl)
+	//CRITICAL: temperature is 92°C, above CRITICAL threshold 90°C
+*/
+func (r *Response) SetThresholdMessageTemplate(tmpl *template.Template) {
+	r.thresholdMessageTemplate = tmpl
+}
+
+// thresholdMessage returns the output message for a threshold violation. It uses the template set with
+// SetThresholdMessageTemplate, if any.
+func (r *Response) thresholdMessage(violation ViolationInfo) string {
+	if r.thresholdMessageTemplate == nil {
+		return violation.Name + " is outside of " + r.statusText(violation.Status) + " threshold"
+	}
+	var buffer bytes.Buffer
+	err := r.thresholdMessageTemplate.Execute(&buffer, violation)
+	if err != nil {
+		r.debug("failed to execute threshold message template", "error", err)
+		return violation.Name + " is outside of " + r.statusText(violation.Status) + " threshold"
+	}
+	return buffer.String()
+}
@@ -0,0 +1,28 @@
+package monitoringplugin
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"text/template"
+)
+
+func TestResponse_SetThresholdMessageTemplate(t *testing.T) {
+	r := NewResponse("checked")
+	r.SetThresholdMessageTemplate(template.Must(template.New("threshold").Parse(
+		"{{.Name}} is {{.ValueText}}{{.Unit}}, {{.Direction}} {{.StatusText}} threshold {{.BoundText}}{{.Unit}}")))
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("temperature", 92).SetUnit("C").
+		SetThresholds(NewThresholds(10, 80, 0, 90))))
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("humidity", 5).SetUnit("%").
+		SetThresholds(NewThresholds(10, 80, 0, 90))))
+	assert.NoError(t, r.CheckThresholds(NewThresholds(nil, nil, 1, nil), 0.5, "pressure"))
+	assert.Equal(t, []OutputMessage{
+		{Status: CRITICAL, Message: "temperature is 92C, above CRITICAL threshold 90C"},
+		{Status: WARNING, Message: "humidity is 5%, below WARNING threshold 10%"},
+		{Status: CRITICAL, Message: "pressure is 0.5, below CRITICAL threshold 1"},
+	}, r.outputMessages)
+
+	r = NewResponse("checked")
+	r.SetThresholdMessageTemplate(template.Must(template.New("threshold").Parse("{{.Missing}}")))
+	assert.NoError(t, r.CheckThresholds(NewThresholds(nil, 10, nil, 20), 15, "load"))
+	assert.Equal(t, "load is outside of WARNING threshold", r.outputMessages[0].Message)
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"github.com/pkg/errors"
 	"math/big"
-	"strconv"
 )
 
 // Thresholds contains all threshold values
@@ -13,6 +12,10 @@ type Thresholds struct {
 	WarningMax  interface{} `json:"warningMax" xml:"warningMax"`
 	CriticalMin interface{} `json:"criticalMin" xml:"criticalMin"`
 	CriticalMax interface{} `json:"criticalMax" xml:"criticalMax"`
+	// WarningInside inverts the warning range, so a warning is raised if the value is inside the range ("@10:20").
+	WarningInside bool `json:"warningInside,omitempty" xml:"warningInside,omitempty"`
+	// CriticalInside inverts the critical range, so the status is critical if the value is inside the range.
+	CriticalInside bool `json:"criticalInside,omitempty" xml:"criticalInside,omitempty"`
 }
 
 // NewThresholds creates a new threshold
@@ -25,15 +28,29 @@ func NewThresholds(warningMin, warningMax, criticalMin, criticalMax interface{})
 	}
 }
 
+/*
+NewInsideThresholds creates thresholds that are violated if the value is inside the ranges instead of outside, which
+are rendered as "@min:max" in the performance data.
+Usage:
+	thresholds := NewInsideThresholds(10, 20, 12, 18)
+	//a value between 10 and 20 is WARNING, a value between 12 and 18 is CRITICAL
+*/
+func NewInsideThresholds(warningMin, warningMax, criticalMin, criticalMax interface{}) Thresholds {
+	thresholds := NewThresholds(warningMin, warningMax, criticalMin, criticalMax)
+	thresholds.WarningInside = true
+	thresholds.CriticalInside = true
+	return thresholds
+}
+
 // Validate checks if the Thresholds contains some invalid combination of warning and critical values
 func (c *Thresholds) Validate() error {
 	if c.WarningMin != nil && c.WarningMax != nil {
 		var min, max big.Float
-		_, _, err := min.Parse(fmt.Sprint(c.WarningMin), 10)
+		_, _, err := min.SetPrec(numberPrecision).Parse(fmt.Sprint(c.WarningMin), 10)
 		if err != nil {
 			return errors.Wrap(err, "can't parse warning min")
 		}
-		_, _, err = max.Parse(fmt.Sprint(c.WarningMax), 10)
+		_, _, err = max.SetPrec(numberPrecision).Parse(fmt.Sprint(c.WarningMax), 10)
 		if err != nil {
 			return errors.Wrap(err, "can't parse warning max")
 		}
@@ -45,11 +62,11 @@ func (c *Thresholds) Validate() error {
 
 	if c.CriticalMin != nil && c.CriticalMax != nil {
 		var min, max big.Float
-		_, _, err := min.Parse(fmt.Sprint(c.CriticalMin), 10)
+		_, _, err := min.SetPrec(numberPrecision).Parse(fmt.Sprint(c.CriticalMin), 10)
 		if err != nil {
 			return errors.Wrap(err, "can't parse critical min")
 		}
-		_, _, err = max.Parse(fmt.Sprint(c.CriticalMax), 10)
+		_, _, err = max.SetPrec(numberPrecision).Parse(fmt.Sprint(c.CriticalMax), 10)
 		if err != nil {
 			return errors.Wrap(err, "can't parse critical max")
 		}
@@ -59,13 +76,17 @@ func (c *Thresholds) Validate() error {
 		}
 	}
 
+	if c.WarningInside || c.CriticalInside {
+		return nil
+	}
+
 	if c.CriticalMin != nil && c.WarningMin != nil {
 		var wMin, cMin big.Float
-		_, _, err := wMin.Parse(fmt.Sprint(c.WarningMin), 10)
+		_, _, err := wMin.SetPrec(numberPrecision).Parse(fmt.Sprint(c.WarningMin), 10)
 		if err != nil {
 			return errors.Wrap(err, "can't parse warning min")
 		}
-		_, _, err = cMin.Parse(fmt.Sprint(c.CriticalMin), 10)
+		_, _, err = cMin.SetPrec(numberPrecision).Parse(fmt.Sprint(c.CriticalMin), 10)
 		if err != nil {
 			return errors.Wrap(err, "can't parse critical min")
 		}
@@ -77,11 +98,11 @@ func (c *Thresholds) Validate() error {
 
 	if c.WarningMax != nil && c.CriticalMax != nil {
 		var wMax, cMax big.Float
-		_, _, err := wMax.Parse(fmt.Sprint(c.WarningMax), 10)
+		_, _, err := wMax.SetPrec(numberPrecision).Parse(fmt.Sprint(c.WarningMax), 10)
 		if err != nil {
 			return errors.Wrap(err, "can't parse warning min")
 		}
-		_, _, err = cMax.Parse(fmt.Sprint(c.CriticalMax), 10)
+		_, _, err = cMax.SetPrec(numberPrecision).Parse(fmt.Sprint(c.CriticalMax), 10)
 		if err != nil {
 			return errors.Wrap(err, "can't parse critical min")
 		}
@@ -110,74 +131,86 @@ func (c *Thresholds) IsEmpty() bool {
 }
 
 // CheckValue checks if the input is violating the thresholds
-func (c *Thresholds) CheckValue(v interface{}) (int, error) {
-	var value, wMin, wMax, cMin, cMax big.Float
-	_, _, err := value.Parse(fmt.Sprint(v), 10)
+func (c *Thresholds) CheckValue(v interface{}) (Status, error) {
+	var value big.Float
+	_, _, err := value.SetPrec(numberPrecision).Parse(fmt.Sprint(v), 10)
 	if err != nil {
 		return 0, errors.Wrap(err, "value can't be parsed")
 	}
-	if c.CriticalMin != nil {
-		_, _, err := cMin.Parse(fmt.Sprint(c.CriticalMin), 10)
-		if err != nil {
-			return 0, errors.Wrap(err, "critical min can't be parsed")
-		}
-		if cMin.Cmp(&value) == 1 {
-			return CRITICAL, nil
-		}
+	violated, err := rangeViolated(c.CriticalMin, c.CriticalMax, c.CriticalInside, &value)
+	if err != nil {
+		return 0, errors.Wrap(err, "critical threshold can't be parsed")
 	}
-	if c.CriticalMax != nil {
-		_, _, err := cMax.Parse(fmt.Sprint(c.CriticalMax), 10)
-		if err != nil {
-			return 0, errors.Wrap(err, "critical max can't be parsed")
-		}
-		if cMax.Cmp(&value) == -1 {
-			return CRITICAL, nil
-		}
+	if violated {
+		return CRITICAL, nil
 	}
-	if c.WarningMin != nil {
-		_, _, err := wMin.Parse(fmt.Sprint(c.WarningMin), 10)
-		if err != nil {
-			return 0, errors.Wrap(err, "warning min can't be parsed")
-		}
-		if wMin.Cmp(&value) == 1 {
-			return WARNING, nil
-		}
+	violated, err = rangeViolated(c.WarningMin, c.WarningMax, c.WarningInside, &value)
+	if err != nil {
+		return 0, errors.Wrap(err, "warning threshold can't be parsed")
 	}
-	if c.WarningMax != nil {
-		_, _, err := wMax.Parse(fmt.Sprint(c.WarningMax), 10)
-		if err != nil {
-			return 0, errors.Wrap(err, "warning max can't be parsed")
+	if violated {
+		return WARNING, nil
+	}
+	return OK, nil
+}
+
+// rangeViolated checks if the value is outside the range of min and max, or inside if inside is true. A bound that is
+// nil is infinite. If both bounds are nil, the range is not set and never violated.
+func rangeViolated(min, max interface{}, inside bool, value *big.Float) (bool, error) {
+	if min == nil && max == nil {
+		return false, nil
+	}
+	below, above := false, false
+	if min != nil {
+		var m big.Float
+		if _, _, err := m.SetPrec(numberPrecision).Parse(fmt.Sprint(min), 10); err != nil {
+			return false, errors.Wrap(err, "min can't be parsed")
 		}
-		if wMax.Cmp(&value) == -1 {
-			return WARNING, nil
+		below = m.Cmp(value) == 1
+	}
+	if max != nil {
+		var m big.Float
+		if _, _, err := m.SetPrec(numberPrecision).Parse(fmt.Sprint(max), 10); err != nil {
+			return false, errors.Wrap(err, "max can't be parsed")
 		}
+		above = m.Cmp(value) == -1
 	}
-	return OK, nil
+	outside := below || above
+	return outside != inside, nil
+}
+
+// outside returns a copy of the thresholds without inside ranges.
+func (c Thresholds) outside() Thresholds {
+	if c.WarningInside {
+		c.WarningMin, c.WarningMax, c.WarningInside = nil, nil, false
+	}
+	if c.CriticalInside {
+		c.CriticalMin, c.CriticalMax, c.CriticalInside = nil, nil, false
+	}
+	return c
 }
 
 func (c *Thresholds) getWarning() string {
-	return getRange(c.WarningMin, c.WarningMax)
+	return getRange(c.WarningMin, c.WarningMax, c.WarningInside)
 }
 
 func (c *Thresholds) getCritical() string {
-	return getRange(c.CriticalMin, c.CriticalMax)
+	return getRange(c.CriticalMin, c.CriticalMax, c.CriticalInside)
 }
 
-func getRange(min, max interface{}) string {
+// getRange returns the range in the Nagios range format, e.g. "10", "10:", "~:10", "10:20" or "@10:20".
+func getRange(min, max interface{}, inside bool) string {
 	if min == nil && max == nil {
 		return ""
 	}
 
 	var res string
+	if inside {
+		res = "@"
+	}
 
 	if min != nil {
-		var minString string
-		switch m := min.(type) {
-		case float64:
-			minString = strconv.FormatFloat(m, 'f', -1, 64)
-		default:
-			minString = fmt.Sprint(m)
-		}
+		minString := formatNumber(min)
 		if minString != "0" || max == nil {
 			res += minString + ":"
 		}
@@ -186,14 +219,7 @@ func getRange(min, max interface{}) string {
 	}
 
 	if max != nil {
-		var maxString string
-		switch m := max.(type) {
-		case float64:
-			maxString = strconv.FormatFloat(m, 'f', -1, 64)
-		default:
-			maxString = fmt.Sprint(m)
-		}
-		res += maxString
+		res += formatNumber(max)
 	}
 
 	return res
@@ -0,0 +1,164 @@
+package monitoringplugin
+
+import (
+	"github.com/pkg/errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Range is a range in the format of the Monitoring Plugins Development Guidelines. A bound that is nil is infinite.
+// If Inside is true, the range alerts if the value is inside the range instead of outside.
+type Range struct {
+	Min    interface{}
+	Max    interface{}
+	Inside bool
+}
+
+/*
+ParseRange parses a range in the format of the Monitoring Plugins Development Guidelines, e.g. "10" (0 to 10), "10:"
+(10 to infinity), "~:10" (negative infinity to 10), "10:20" and "@10:20" (inside 10 to 20). Integers are parsed as
+int64, all other numbers as float64. An empty string results in an empty Range.
+Usage:
+	r, err := ParseRange("@10:20")
+	//r.Min == int64(10), r.Max == int64(20), r.Inside == true
+*/
+func ParseRange(s string) (Range, error) {
+	return parseRange(s, "")
+}
+
+/*
+ParseRangeWithUnit parses a range like ParseRange, but the bounds of the range may have a unit of measurement, which is
+converted to the given unit using the registered unit conversions (see RegisterUnitConversion). Bounds without a unit
+are already in the given unit.
+Usage:
+	r, err := ParseRangeWithUnit("~:1.5s", "ms")
+	//r.Max == float64(1500)
+*/
+func ParseRangeWithUnit(s, unit string) (Range, error) {
+	return parseRange(s, unit)
+}
+
+// parseRange parses a range and converts bounds with a unit to the given unit.
+func parseRange(s, unit string) (Range, error) {
+	var res Range
+	text := strings.TrimSpace(s)
+	if text == "" {
+		return res, nil
+	}
+	if strings.HasPrefix(text, "@") {
+		res.Inside = true
+		text = text[1:]
+		if text == "" {
+			return Range{}, errors.Errorf("range '%s' has no bounds", s)
+		}
+	}
+
+	start, end := "0", text
+	if i := strings.IndexByte(text, ':'); i >= 0 {
+		start, end = text[:i], text[i+1:]
+	}
+	if start != "~" {
+		min, err := parseRangeNumber(start, unit)
+		if err != nil {
+			return Range{}, errors.Wrapf(err, "invalid start of range '%s'", s)
+		}
+		res.Min = min
+	}
+	if end != "" {
+		max, err := parseRangeNumber(end, unit)
+		if err != nil {
+			return Range{}, errors.Wrapf(err, "invalid end of range '%s'", s)
+		}
+		res.Max = max
+	}
+	if res.Min == nil && res.Max == nil {
+		return Range{}, errors.Errorf("range '%s' has no bounds", s)
+	}
+	if res.Min != nil && res.Max != nil {
+		min, _ := parseFloat(res.Min)
+		max, _ := parseFloat(res.Max)
+		if min > max {
+			return Range{}, errors.Errorf("start of range '%s' is larger than its end", s)
+		}
+	}
+	return res, nil
+}
+
+// rangeNumberWithUnit splits a bound of a range into the number and its unit of measurement.
+var rangeNumberWithUnit = regexp.MustCompile(`^([+-]?(?:[0-9]+\.?[0-9]*|\.[0-9]+)(?:[eE][+-]?[0-9]+)?)([^0-9.].*)$`)
+
+// parseRangeNumber parses a bound of a range as int64 if possible, otherwise as float64. If unit is set and the bound
+// has a different unit, the bound is converted to unit.
+func parseRangeNumber(s, unit string) (interface{}, error) {
+	if unit != "" {
+		if m := rangeNumberWithUnit.FindStringSubmatch(s); m != nil && m[2] != unit {
+			f, err := strconv.ParseFloat(m[1], 64)
+			if err != nil {
+				return nil, errors.Errorf("'%s' is not a number", m[1])
+			}
+			converted, err := ConvertUnit(f, m[2], unit)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to convert '%s'", s)
+			}
+			return converted, nil
+		} else if m != nil {
+			s = m[1]
+		}
+	}
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, errors.Errorf("'%s' is not a number", s)
+	}
+	return f, nil
+}
+
+/*
+ParseThresholds parses a range (see ParseRange), e.g. the -c argument of a check plugin, and returns Thresholds with the
+range as critical threshold. The Thresholds are validated (see Thresholds.Validate()). To use a warning threshold as
+well, e.g. for the standard -w and -c arguments, the ranges are combined with NewRangeThresholds.
+Usage:
+	thresholds, err := ParseThresholds("10:20")
+	if err != nil {
+		...
+	}
+	err = Response.AddPerformanceDataPoint(NewPerformanceDataPoint("load", load).SetThresholds(thresholds))
+*/
+func ParseThresholds(s string) (Thresholds, error) {
+	r, err := ParseRange(s)
+	if err != nil {
+		return Thresholds{}, errors.Wrap(err, "invalid critical threshold")
+	}
+	thresholds := NewRangeThresholds(Range{}, r)
+	if err := thresholds.Validate(); err != nil {
+		return Thresholds{}, errors.Wrap(err, "invalid thresholds")
+	}
+	return thresholds, nil
+}
+
+/*
+NewRangeThresholds creates thresholds from a warning and a critical range. An empty Range leaves the respective
+threshold unset. The result should be checked with Thresholds.Validate(), because the ranges are not compared.
+Usage:
+	warning, err := ParseRange(*w)
+	...
+	critical, err := ParseRange(*c)
+	...
+	thresholds := NewRangeThresholds(warning, critical)
+	if err := thresholds.Validate(); err != nil {
+		...
+	}
+*/
+func NewRangeThresholds(warning, critical Range) Thresholds {
+	return Thresholds{
+		WarningMin:     warning.Min,
+		WarningMax:     warning.Max,
+		CriticalMin:    critical.Min,
+		CriticalMax:    critical.Max,
+		WarningInside:  warning.Inside,
+		CriticalInside: critical.Inside,
+	}
+}
@@ -0,0 +1,80 @@
+package monitoringplugin
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseRange(t *testing.T) {
+	tests := map[string]Range{
+		"":        {},
+		"10":      {Min: int64(0), Max: int64(10)},
+		"10:":     {Min: int64(10)},
+		"~:10":    {Max: int64(10)},
+		"10:20":   {Min: int64(10), Max: int64(20)},
+		"-5.5:10": {Min: -5.5, Max: int64(10)},
+		"@10:20":  {Min: int64(10), Max: int64(20), Inside: true},
+		"@~:0":    {Max: int64(0), Inside: true},
+	}
+	for s, expected := range tests {
+		r, err := ParseRange(s)
+		if assert.NoError(t, err, s) {
+			assert.Equal(t, expected, r, s)
+		}
+	}
+
+	for _, s := range []string{"abc", "10:abc", "~:", "~", "20:10", "@"} {
+		_, err := ParseRange(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestParseRangeWithUnit(t *testing.T) {
+	tests := map[string]Range{
+		"~:1.5s":     {Max: 1500.0},
+		"500:1s":     {Min: int64(500), Max: 1000.0},
+		"@100us:2ms": {Min: 0.1, Max: int64(2), Inside: true},
+		"1ms:1e1ms":  {Min: int64(1), Max: 10.0},
+	}
+	for s, expected := range tests {
+		r, err := ParseRangeWithUnit(s, "ms")
+		if assert.NoError(t, err, s) {
+			assert.Equal(t, expected, r, s)
+		}
+	}
+
+	for _, s := range []string{"10KB", "10ms:abc", "~:10parsecs"} {
+		_, err := ParseRangeWithUnit(s, "ms")
+		assert.Error(t, err, s)
+	}
+}
+
+func TestParseThresholds(t *testing.T) {
+	thresholds, err := ParseThresholds("@90:95")
+	assert.NoError(t, err)
+	assert.Equal(t, Thresholds{CriticalMin: int64(90), CriticalMax: int64(95), CriticalInside: true}, thresholds)
+
+	for _, s := range []string{"10", "10:", "~:10", "10:20", "@10:20", "-5.5:10"} {
+		thresholds, err = ParseThresholds(s)
+		if assert.NoError(t, err) {
+			assert.Equal(t, s, thresholds.getCritical())
+			assert.False(t, thresholds.HasWarning())
+		}
+	}
+
+	_, err = ParseThresholds("x")
+	assert.Error(t, err)
+}
+
+func TestNewRangeThresholds(t *testing.T) {
+	warning, err := ParseRange("~:80")
+	assert.NoError(t, err)
+	critical, err := ParseRange("~:90")
+	assert.NoError(t, err)
+	thresholds := NewRangeThresholds(warning, critical)
+	assert.Equal(t, Thresholds{WarningMax: int64(80), CriticalMax: int64(90)}, thresholds)
+	assert.NoError(t, thresholds.Validate())
+
+	thresholds = NewRangeThresholds(critical, warning)
+	assert.Error(t, thresholds.Validate())
+}
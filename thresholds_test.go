@@ -110,3 +110,47 @@ func TestCheckThresholds(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, CRITICAL, res)
 }
+
+func TestCheckInsideThresholds(t *testing.T) {
+	th := NewInsideThresholds(10, 20, 12, 18)
+	assert.NoError(t, th.Validate())
+
+	res, err := th.CheckValue(9)
+	assert.NoError(t, err)
+	assert.Equal(t, OK, res)
+
+	res, err = th.CheckValue(10)
+	assert.NoError(t, err)
+	assert.Equal(t, WARNING, res)
+
+	res, err = th.CheckValue(15)
+	assert.NoError(t, err)
+	assert.Equal(t, CRITICAL, res)
+
+	res, err = th.CheckValue(21)
+	assert.NoError(t, err)
+	assert.Equal(t, OK, res)
+
+	th = NewThresholds(nil, 10, nil, 20)
+	th.WarningInside = true
+	res, err = th.CheckValue(-5)
+	assert.NoError(t, err)
+	assert.Equal(t, WARNING, res)
+
+	res, err = th.CheckValue(25)
+	assert.NoError(t, err)
+	assert.Equal(t, CRITICAL, res)
+}
+
+func TestGetRange(t *testing.T) {
+	assert.Equal(t, "10", getRange(0, 10, false))
+	assert.Equal(t, "10:", getRange(10, nil, false))
+	assert.Equal(t, "~:10", getRange(nil, 10, false))
+	assert.Equal(t, "-10:20", getRange(-10, 20, false))
+	assert.Equal(t, "@10:20", getRange(10, 20, true))
+	assert.Equal(t, "@~:-5", getRange(nil, -5, true))
+	assert.Equal(t, "@10", getRange(0, 10, true))
+
+	p := NewPerformanceDataPoint("temperature", 30).SetThresholds(NewInsideThresholds(10, 20, 12, 18))
+	assert.Equal(t, "'temperature'=30;@10:20;@12:18;;", string(p.output(false)))
+}
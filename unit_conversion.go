@@ -0,0 +1,155 @@
+package monitoringplugin
+
+import (
+	"github.com/pkg/errors"
+	"sync"
+)
+
+// unitConversions maps a unit to all units it can be converted to directly and the factor that is used to do so. It is
+// kept separate from the units that are accepted in strict unit mode (see RegisterUnit(string)), so registering a
+// conversion does not make its units valid.
+var unitConversions = struct {
+	sync.RWMutex
+	factors map[string]map[string]float64
+}{
+	factors: make(map[string]map[string]float64),
+}
+
+func init() {
+	builtin := []struct {
+		from, to string
+		factor   float64
+	}{
+		{"us", "ms", 0.001},
+		{"ms", "s", 0.001},
+		{"s", "min", 1.0 / 60},
+		{"min", "h", 1.0 / 60},
+		{"h", "d", 1.0 / 24},
+		{"B", "KB", 1.0 / 1024},
+		{"KB", "MB", 1.0 / 1024},
+		{"MB", "GB", 1.0 / 1024},
+		{"GB", "TB", 1.0 / 1024},
+		{"TB", "PB", 1.0 / 1024},
+		{"B", "KiB", 1.0 / 1024},
+		{"KiB", "MiB", 1.0 / 1024},
+		{"MiB", "GiB", 1.0 / 1024},
+		{"GiB", "TiB", 1.0 / 1024},
+		{"TiB", "PiB", 1.0 / 1024},
+	}
+	for _, c := range builtin {
+		_ = RegisterUnitConversion(c.from, c.to, c.factor)
+	}
+}
+
+/*
+RegisterUnitConversion registers a conversion between two units. A value in the unit from multiplied with the factor
+results in the value in the unit to. The inverse conversion is registered automatically. The units are not accepted in
+strict unit mode unless they are guideline units or registered with RegisterUnit(string).
+Conversions for time (us, ms, s, min, h, d) and bytes (B, KB, MB, GB, TB, PB and KiB, MiB, GiB, TiB, PiB) are
+registered by default. KB, MB, etc. are based on 1024 like their binary counterparts.
+The registered conversions are used by ConvertUnit, PerformanceDataPoint.ConvertUnit and ParseRangeWithUnit to convert
+values and threshold ranges.
+Usage:
+	err := RegisterUnitConversion("req/min", "req/s", 1.0/60)
+	if err != nil {
+		...
+	}
+*/
+func RegisterUnitConversion(from, to string, factor float64) error {
+	if err := checkUnit(from); err != nil {
+		return err
+	}
+	if err := checkUnit(to); err != nil {
+		return err
+	}
+	if from == to {
+		return errors.New("cannot register a conversion of a unit to itself")
+	}
+	if factor == 0 {
+		return errors.New("factor cannot be 0")
+	}
+
+	unitConversions.Lock()
+	defer unitConversions.Unlock()
+	setUnitConversionFactor(from, to, factor)
+	setUnitConversionFactor(to, from, 1/factor)
+	return nil
+}
+
+func setUnitConversionFactor(from, to string, factor float64) {
+	if unitConversions.factors[from] == nil {
+		unitConversions.factors[from] = make(map[string]float64)
+	}
+	unitConversions.factors[from][to] = factor
+}
+
+/*
+ConvertUnit converts a value from one unit to another using the registered unit conversions.
+Conversions can be chained, e.g. a value in ms can be converted to h via s and min.
+Usage:
+	seconds, err := ConvertUnit(1500, "ms", "s") // seconds = 1.5
+*/
+func ConvertUnit(value float64, from, to string) (float64, error) {
+	if from == to {
+		return value, nil
+	}
+
+	unitConversions.RLock()
+	defer unitConversions.RUnlock()
+
+	// breadth-first search for the shortest chain of conversions
+	factors := map[string]float64{from: 1}
+	queue := []string{from}
+	for len(queue) > 0 {
+		unit := queue[0]
+		queue = queue[1:]
+		for next, factor := range unitConversions.factors[unit] {
+			if _, ok := factors[next]; ok {
+				continue
+			}
+			factors[next] = factors[unit] * factor
+			if next == to {
+				return value * factors[next], nil
+			}
+			queue = append(queue, next)
+		}
+	}
+	return 0, errors.New("no conversion from unit '" + from + "' to unit '" + to + "' registered")
+}
+
+/*
+ConvertUnit converts the value, min, max and thresholds of the performance data point from one unit to another using
+the registered unit conversions (see RegisterUnitConversion) and sets the unit to the target unit. If the unit of the
+point is set, it must match the unit that is converted from. If a value can not be converted, the point is not
+changed.
+Usage:
+	p := NewPerformanceDataPoint("response_time", 1500).SetThresholds(NewThresholds(nil, 1000, nil, 2000))
+	err := p.ConvertUnit("ms", "s")
+	//'response_time'=1.5s;~:1;~:2;;
+*/
+func (p *PerformanceDataPoint) ConvertUnit(from, to string) error {
+	if p.Unit != "" && p.Unit != from {
+		return errors.New("unit of the performance data point is '" + p.Unit + "', not '" + from + "'")
+	}
+	values := []*interface{}{&p.Value, &p.Min, &p.Max, &p.Thresholds.WarningMin, &p.Thresholds.WarningMax,
+		&p.Thresholds.CriticalMin, &p.Thresholds.CriticalMax}
+	converted := make([]interface{}, len(values))
+	for i, v := range values {
+		if *v == nil || v == &p.Value && p.IsValueUnknown() {
+			converted[i] = *v
+			continue
+		}
+		f, err := parseFloat(*v)
+		if err != nil {
+			return errors.Wrap(err, "failed to parse value")
+		}
+		if converted[i], err = ConvertUnit(f, from, to); err != nil {
+			return err
+		}
+	}
+	for i, v := range values {
+		*v = converted[i]
+	}
+	p.Unit = to
+	return nil
+}
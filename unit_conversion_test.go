@@ -0,0 +1,60 @@
+package monitoringplugin
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestConvertUnit(t *testing.T) {
+	res, err := ConvertUnit(1500, "ms", "s")
+	assert.NoError(t, err)
+	assert.InDelta(t, 1.5, res, 1e-9)
+
+	res, err = ConvertUnit(2, "h", "ms")
+	assert.NoError(t, err)
+	assert.InDelta(t, 7200000, res, 1e-6)
+
+	res, err = ConvertUnit(3, "GB", "MB")
+	assert.NoError(t, err)
+	assert.InDelta(t, 3072, res, 1e-9)
+
+	res, err = ConvertUnit(42, "%", "%")
+	assert.NoError(t, err)
+	assert.Equal(t, float64(42), res)
+
+	_, err = ConvertUnit(1, "s", "B")
+	assert.Error(t, err)
+}
+
+func TestRegisterUnitConversion(t *testing.T) {
+	assert.Error(t, RegisterUnitConversion("", "s", 1))
+	assert.Error(t, RegisterUnitConversion("conns", "conns", 1))
+	assert.Error(t, RegisterUnitConversion("req/min", "req/s", 0))
+
+	assert.NoError(t, RegisterUnitConversion("req/min", "req/s", 1.0/60))
+	res, err := ConvertUnit(120, "req/min", "req/s")
+	assert.NoError(t, err)
+	assert.InDelta(t, 2, res, 1e-9)
+	res, err = ConvertUnit(2, "req/s", "req/min")
+	assert.NoError(t, err)
+	assert.InDelta(t, 120, res, 1e-9)
+}
+
+func TestPerformanceDataPoint_ConvertUnit(t *testing.T) {
+	p := NewPerformanceDataPoint("response_time", 1500).SetMin(0).SetThresholds(NewThresholds(nil, 1000, nil, 2000))
+	assert.NoError(t, p.ConvertUnit("ms", "s"))
+	assert.Equal(t, "'response_time'=1.5s;~:1;~:2;0;", string(p.output(false)))
+
+	p = NewPerformanceDataPoint("memory", 2048).SetUnit("KiB").SetMax(4096)
+	assert.NoError(t, p.ConvertUnit("KiB", "MiB"))
+	assert.Equal(t, "'memory'=2MiB;;;;4", string(p.output(false)))
+
+	p = NewPerformanceDataPoint("memory", 2048).SetUnit("KB")
+	assert.Error(t, p.ConvertUnit("MB", "GB"))
+	assert.Error(t, p.ConvertUnit("KB", "s"))
+	assert.Equal(t, "'memory'=2048KB", string(p.output(false)))
+
+	p = NewUnknownPerformanceDataPoint("response_time").SetMax(1000)
+	assert.NoError(t, p.ConvertUnit("ms", "s"))
+	assert.Equal(t, "'response_time'=Us;;;;1", string(p.output(false)))
+}
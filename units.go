@@ -0,0 +1,96 @@
+package monitoringplugin
+
+import (
+	"github.com/pkg/errors"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// unitRegistry contains the units of measurement that are accepted in strict unit mode.
+var unitRegistry = struct {
+	sync.RWMutex
+	units map[string]bool
+}{
+	units: make(map[string]bool),
+}
+
+// guidelineUnits contains the units of measurement that are defined in the Monitoring Plugins Development Guidelines.
+var guidelineUnits = []string{"s", "us", "ms", "%", "B", "KB", "MB", "GB", "TB", "c"}
+
+func init() {
+	unitRegistry.Lock()
+	defer unitRegistry.Unlock()
+	for _, unit := range guidelineUnits {
+		unitRegistry.units[unit] = true
+	}
+}
+
+var invalidUnitCharacters = regexp.MustCompile("[0-9;'\"]")
+
+/*
+RegisterUnit registers a user-defined unit of measurement that is accepted in strict unit mode in addition to the units
+of the Monitoring Plugins Development Guidelines (s, us, ms, %, B, KB, MB, GB, TB, c). Units of a unit conversion
+(see RegisterUnitConversion(string, string, float64)) that are not guideline units have to be registered as well.
+Usage:
+	err := RegisterUnit("req/s")
+	if err != nil {
+		...
+	}
+*/
+func RegisterUnit(unit string) error {
+	if err := checkUnit(unit); err != nil {
+		return err
+	}
+	unitRegistry.Lock()
+	defer unitRegistry.Unlock()
+	unitRegistry.units[unit] = true
+	return nil
+}
+
+// checkUnit returns an error if the unit can not be registered.
+func checkUnit(unit string) error {
+	if unit == "" {
+		return errors.New("unit cannot be an empty string")
+	}
+	if invalidUnitCharacters.MatchString(unit) || strings.ContainsAny(unit, " =|") {
+		return errors.New("unit can not contain numbers, semicolon, quotes, spaces, equal signs or pipes")
+	}
+	return nil
+}
+
+// IsValidUnit checks if the unit is a unit of the guidelines or a registered unit. An empty unit is always valid.
+func IsValidUnit(unit string) bool {
+	if unit == "" {
+		return true
+	}
+	unitRegistry.RLock()
+	defer unitRegistry.RUnlock()
+	return unitRegistry.units[unit]
+}
+
+// validateUnit returns an error if the unit of the PerformanceDataPoint is not a valid unit (see IsValidUnit(string)).
+func (p *PerformanceDataPoint) validateUnit() error {
+	if IsValidUnit(p.Unit) {
+		return nil
+	}
+	unitRegistry.RLock()
+	units := make([]string, 0, len(unitRegistry.units))
+	for unit := range unitRegistry.units {
+		units = append(units, unit)
+	}
+	unitRegistry.RUnlock()
+	sort.Strings(units)
+	return p.validationError("unit", "unit '"+p.Unit+"' is not a valid unit of measurement (valid units: "+
+		strings.Join(units, ", ")+"), use RegisterUnit to add it", nil)
+}
+
+/*
+SetStrictUnits activates or deactivates the strict unit mode. In strict unit mode, AddPerformanceDataPoint returns an
+error if the unit of a PerformanceDataPoint is neither one of the units of the Monitoring Plugins Development
+Guidelines nor was registered with RegisterUnit(string), instead of silently accepting units that can't be graphed.
+*/
+func (r *Response) SetStrictUnits(b bool) {
+	r.strictUnits = b
+}
@@ -0,0 +1,44 @@
+package monitoringplugin
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRegisterUnit(t *testing.T) {
+	assert.True(t, IsValidUnit(""))
+	assert.True(t, IsValidUnit("KB"))
+	assert.True(t, IsValidUnit("c"))
+	assert.False(t, IsValidUnit("req/h"))
+
+	assert.NoError(t, RegisterUnit("req/h"))
+	assert.True(t, IsValidUnit("req/h"))
+
+	assert.Error(t, RegisterUnit(""))
+	assert.Error(t, RegisterUnit("k b"))
+	assert.Error(t, RegisterUnit("x1"))
+	assert.Error(t, RegisterUnit("a|b"))
+}
+
+func TestResponse_SetStrictUnits(t *testing.T) {
+	r := NewResponse("checked")
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("temperature", 32).SetUnit("°C")))
+
+	r.SetStrictUnits(true)
+	err := r.AddPerformanceDataPoint(NewPerformanceDataPoint("temperature2", 32).SetUnit("°C"))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "unit '°C' is not a valid unit of measurement")
+	}
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("usage", 32).SetUnit("%")))
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("count", 32)))
+
+	assert.NoError(t, RegisterUnit("°F"))
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("temperature3", 90).SetUnit("°F")))
+
+	assert.Error(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("memory", 3).SetUnit("KiB")))
+	assert.Error(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("uptime_hours", 3).SetUnit("h")))
+	assert.NoError(t, RegisterUnitConversion("d", "weeks", 1.0/7))
+	assert.Error(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("uptime", 3).SetUnit("weeks")))
+	assert.NoError(t, RegisterUnit("weeks"))
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("uptime", 3).SetUnit("weeks")))
+}
@@ -0,0 +1,63 @@
+package monitoringplugin
+
+/*
+ValidationError is returned if a PerformanceDataPoint is not valid, e.g. by Validate() or AddPerformanceDataPoint. It
+identifies the offending point and field, so callers can decide to drop, fix or escalate programmatically.
+Field is one of "metric", "label", "unit", "value", "min", "max" and "thresholds".
+Usage:
+	err := Response.AddPerformanceDataPoint(point)
+	if validationErr, ok := AsValidationError(err); ok && validationErr.Field == "max" {
+		err = Response.AddPerformanceDataPoint(point.SetMax(nil))
+	}
+*/
+type ValidationError struct {
+	Metric string
+	Label  string
+	Field  string
+	Reason string
+	// Err is the underlying error, e.g. a parse error. It may be nil.
+	Err error
+}
+
+// Error returns the error message, which contains the name of the performance data point, the field and the reason.
+func (e *ValidationError) Error() string {
+	name := e.Metric
+	if e.Label != "" {
+		name += " (" + e.Label + ")"
+	}
+	text := "performance data point '" + name + "' has an invalid " + e.Field + ": " + e.Reason
+	if e.Err != nil {
+		text += ": " + e.Err.Error()
+	}
+	return text
+}
+
+// Unwrap returns the underlying error.
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+/*
+AsValidationError returns the ValidationError in the chain of wrapped errors of err, supporting both the standard
+library and github.com/pkg/errors. It returns false if there is none.
+*/
+func AsValidationError(err error) (*ValidationError, bool) {
+	for err != nil {
+		if validationErr, ok := err.(*ValidationError); ok {
+			return validationErr, true
+		}
+		err = unwrapError(err)
+	}
+	return nil, false
+}
+
+// validationError returns a ValidationError for the given field of the PerformanceDataPoint.
+func (p *PerformanceDataPoint) validationError(field, reason string, err error) *ValidationError {
+	return &ValidationError{
+		Metric: p.Metric,
+		Label:  p.Label,
+		Field:  field,
+		Reason: reason,
+		Err:    err,
+	}
+}
@@ -0,0 +1,41 @@
+package monitoringplugin
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestValidationError(t *testing.T) {
+	r := NewResponse("checked")
+	err := r.AddPerformanceDataPoint(NewPerformanceDataPoint("disk", 120).SetLabel("/var").SetMax(100))
+	validationErr, ok := AsValidationError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, "disk", validationErr.Metric)
+		assert.Equal(t, "/var", validationErr.Label)
+		assert.Equal(t, "max", validationErr.Field)
+		assert.Equal(t, "value cannot be larger than max", validationErr.Reason)
+	}
+	assert.Contains(t, err.Error(), "performance data point 'disk (/var)' has an invalid max")
+
+	err = NewPerformanceDataPoint("disk", "abc").Validate()
+	validationErr, ok = AsValidationError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, "value", validationErr.Field)
+		assert.Error(t, validationErr.Unwrap())
+	}
+
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("load", 1)))
+	validationErr, ok = AsValidationError(r.AddPerformanceDataPoint(NewPerformanceDataPoint("load", 2)))
+	if assert.True(t, ok) {
+		assert.Equal(t, "metric", validationErr.Field)
+	}
+
+	r.SetStrictUnits(true)
+	validationErr, ok = AsValidationError(r.AddPerformanceDataPoint(NewPerformanceDataPoint("speed", 2).SetUnit("km/h")))
+	if assert.True(t, ok) {
+		assert.Equal(t, "unit", validationErr.Field)
+	}
+
+	_, ok = AsValidationError(assert.AnError)
+	assert.False(t, ok)
+}
@@ -0,0 +1,98 @@
+package monitoringplugin
+
+import (
+	"github.com/pkg/errors"
+	"math"
+)
+
+// ViolationInfo contains detailed information about a violated threshold.
+type ViolationInfo struct {
+	// Name is the name of the checked value, e.g. the metric of a PerformanceDataPoint.
+	Name string `yaml:"name" json:"name" xml:"name"`
+	// Unit is the unit of the value, if known.
+	Unit string `yaml:"unit,omitempty" json:"unit,omitempty" xml:"unit,omitempty"`
+	// Status is the status that results from the violation.
+	Status Status `yaml:"status" json:"status" xml:"status"`
+	// StatusText is the text of the status, respecting custom status labels (see Response.SetStatusLabels). It is only
+	// set for violations that are recorded by a Response.
+	StatusText string `yaml:"status_text,omitempty" json:"status_text,omitempty" xml:"status_text,omitempty"`
+	// Value is the value that violates the threshold.
+	Value float64 `yaml:"value" json:"value" xml:"value"`
+	// Bound is the threshold that was violated.
+	Bound float64 `yaml:"bound" json:"bound" xml:"bound"`
+	// Direction is "above" if the value is larger than the bound, "inside" if the value is inside an inside range
+	// (see Thresholds.WarningInside), otherwise "below".
+	Direction string `yaml:"direction" json:"direction" xml:"direction"`
+	// Deviation is the absolute difference between the value and the bound.
+	Deviation float64 `yaml:"deviation" json:"deviation" xml:"deviation"`
+	// DeviationPercent is the deviation in percent of the bound. It is 0 if the bound is 0.
+	DeviationPercent float64 `yaml:"deviation_percent" json:"deviation_percent" xml:"deviation_percent"`
+}
+
+// ValueText returns the value formatted like in the performance data, e.g. "1500000" instead of "1.5e+06".
+func (v ViolationInfo) ValueText() string {
+	return formatNumber(v.Value)
+}
+
+// BoundText returns the bound formatted like in the performance data.
+func (v ViolationInfo) BoundText() string {
+	return formatNumber(v.Bound)
+}
+
+/*
+CheckViolation checks if the input is violating the thresholds like CheckValue(interface{}), but returns which bound
+was violated and by how much. If no threshold is violated, nil is returned.
+Usage:
+	violation, err := NewThresholds(nil, 80, nil, 90).CheckViolation(99)
+	//violation.Status == CRITICAL, violation.Bound == 90, violation.DeviationPercent == 10
+*/
+func (c *Thresholds) CheckViolation(v interface{}) (*ViolationInfo, error) {
+	status, err := c.CheckValue(v)
+	if err != nil || status == OK {
+		return nil, err
+	}
+	value, err := parseFloat(v)
+	if err != nil {
+		return nil, errors.Wrap(err, "value can't be parsed")
+	}
+
+	min, max, inside := c.WarningMin, c.WarningMax, c.WarningInside
+	if status == CRITICAL {
+		min, max, inside = c.CriticalMin, c.CriticalMax, c.CriticalInside
+	}
+	violation := ViolationInfo{
+		Status:    status,
+		Value:     value,
+		Direction: "below",
+	}
+	bound := min
+	if inside {
+		violation.Direction = "inside"
+		if max != nil {
+			lower, errMin := parseFloat(min)
+			upper, errMax := parseFloat(max)
+			if min == nil || errMin == nil && errMax == nil && upper-value < value-lower {
+				bound = max
+			}
+		}
+	} else if max != nil {
+		if m, err := parseFloat(max); err == nil && (min == nil || value > m) {
+			violation.Direction, bound = "above", max
+		}
+	}
+	violation.Bound, err = parseFloat(bound)
+	if err != nil {
+		return nil, errors.Wrap(err, "threshold can't be parsed")
+	}
+	violation.Deviation = math.Abs(value - violation.Bound)
+	if violation.Bound != 0 {
+		violation.DeviationPercent = violation.Deviation / math.Abs(violation.Bound) * 100
+	}
+	return &violation, nil
+}
+
+// Violations returns detailed information about all thresholds that were violated in CheckThresholds and
+// AddPerformanceDataPoint.
+func (r *Response) Violations() []ViolationInfo {
+	return r.violations
+}
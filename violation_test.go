@@ -0,0 +1,89 @@
+package monitoringplugin
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestThresholds_CheckViolation(t *testing.T) {
+	thresholds := NewThresholds(10, 80, 5, 100)
+
+	violation, err := thresholds.CheckViolation(50)
+	assert.NoError(t, err)
+	assert.Nil(t, violation)
+
+	violation, err = thresholds.CheckViolation(112)
+	assert.NoError(t, err)
+	assert.Equal(t, &ViolationInfo{
+		Status:           CRITICAL,
+		Value:            112,
+		Bound:            100,
+		Direction:        "above",
+		Deviation:        12,
+		DeviationPercent: 12,
+	}, violation)
+
+	violation, err = thresholds.CheckViolation(8)
+	assert.NoError(t, err)
+	assert.Equal(t, &ViolationInfo{
+		Status:           WARNING,
+		Value:            8,
+		Bound:            10,
+		Direction:        "below",
+		Deviation:        2,
+		DeviationPercent: 20,
+	}, violation)
+
+	_, err = thresholds.CheckViolation("abc")
+	assert.Error(t, err)
+}
+
+func TestResponse_Violations(t *testing.T) {
+	r := NewResponse("checked")
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("load", 1).
+		SetThresholds(NewThresholds(nil, 10, nil, 20))))
+	assert.NoError(t, r.AddPerformanceDataPoint(NewPerformanceDataPoint("memory", 90).SetUnit("%").
+		SetThresholds(NewThresholds(nil, 80, nil, 95))))
+	assert.Equal(t, []ViolationInfo{{
+		Name:             "memory",
+		Unit:             "%",
+		Status:           WARNING,
+		StatusText:       "WARNING",
+		Value:            90,
+		Bound:            80,
+		Direction:        "above",
+		Deviation:        10,
+		DeviationPercent: 12.5,
+	}}, r.Violations())
+
+	info := r.GetInfo()
+	assert.Equal(t, r.Violations(), info.Violations)
+	b, err := json.Marshal(info)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), `"violations":[{"name":"memory","unit":"%","status":1,"status_text":"WARNING","value":90,"bound":80,`+
+		`"direction":"above","deviation":10,"deviation_percent":12.5}]`)
+
+	r.Reset()
+	assert.Empty(t, r.Violations())
+}
+
+func TestThresholds_CheckViolationInside(t *testing.T) {
+	th := NewInsideThresholds(10, 20, nil, nil)
+	violation, err := th.CheckViolation(18)
+	assert.NoError(t, err)
+	if assert.NotNil(t, violation) {
+		assert.Equal(t, WARNING, violation.Status)
+		assert.Equal(t, "inside", violation.Direction)
+		assert.Equal(t, 20.0, violation.Bound)
+		assert.Equal(t, 2.0, violation.Deviation)
+	}
+
+	th = NewInsideThresholds(nil, nil, 10, 20)
+	violation, err = th.CheckViolation(11)
+	assert.NoError(t, err)
+	if assert.NotNil(t, violation) {
+		assert.Equal(t, CRITICAL, violation.Status)
+		assert.Equal(t, 10.0, violation.Bound)
+	}
+}